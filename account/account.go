@@ -4,6 +4,7 @@ import (
 	"crypto/ecdsa"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"regexp"
@@ -17,6 +18,13 @@ import (
 	db "github.com/syndtr/goleveldb/leveldb"
 )
 
+// 계정 상태 검증 실패 원인 (errors.Is로 구분)
+var (
+	ErrUnknownAccount    = errors.New("from account must already exist")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrNonceTooLow       = errors.New("nonce too low")
+)
+
 type Account struct {
 	Balance *big.Int
 	Nonce   uint64
@@ -34,7 +42,7 @@ func GetAccount(address string) (*Account, error) {
 	accountValue, err := dbInstance.Get([]byte(accountKey), nil)
 
 	if err != nil {
-		return nil, fmt.Errorf("해당 키 없음 : %v", err)
+		return nil, fmt.Errorf("해당 키 없음 : %w", err)
 	}
 
 	var account Account
@@ -193,15 +201,15 @@ func PublicKeyToBytes(pubKey *secp256k1.PublicKey) []byte {
 	return pubKey.SerializeUncompressed()
 }
 
-// 트랜잭션 내 from의 논스,잔액 확인, to가 DB에 없다면 Init
-func CheckAccountState(from, to, value string, nonce uint64) error {
+// 트랜잭션 내 from의 논스,잔액(value + fee) 확인, to가 DB에 없다면 Init
+func CheckAccountState(from, to, value, fee string, nonce uint64) error {
 	// 1. from 계정이 존재하는지 확인
 	fromExists, err := AccountExists(from)
 	if err != nil {
 		return fmt.Errorf("failed to check if `from` account exists : %v", err)
 	}
 	if !fromExists {
-		return fmt.Errorf("from account must already exist")
+		return ErrUnknownAccount
 	}
 
 	// 2. from의 value 확인
@@ -213,14 +221,19 @@ func CheckAccountState(from, to, value string, nonce uint64) error {
 	if err != nil {
 		return fmt.Errorf("failed to convert value to big.Int: %v", err)
 	}
+	feeBigInt, err := utils.ConvertStringToBigInt(fee)
+	if err != nil {
+		return fmt.Errorf("failed to convert fee to big.Int: %v", err)
+	}
 
-	if fromAccount.Balance.Cmp(valueBigInt) < 0 {
-		return fmt.Errorf("insufficient funds : available balance is %d", fromAccount.Balance)
+	cost := new(big.Int).Add(valueBigInt, feeBigInt)
+	if fromAccount.Balance.Cmp(cost) < 0 {
+		return fmt.Errorf("%w : available balance is %d, value + fee is %d", ErrInsufficientFunds, fromAccount.Balance, cost)
 	}
 
-	// 3. from의 nonce 확인
-	if fromAccount.Nonce > nonce {
-		return fmt.Errorf("nonce mismatch : expected %d, got %d", fromAccount.Nonce, nonce)
+	// 3. from의 nonce 확인 (Account.Nonce는 마지막으로 사용된 nonce)
+	if nonce <= fromAccount.Nonce {
+		return fmt.Errorf("%w : expected %d, got %d", ErrNonceTooLow, fromAccount.Nonce, nonce)
 	}
 
 	// 4. to 계정이 없다면 생성해주기
@@ -5,15 +5,22 @@ import (
 	"fmt"
 	"math/big"
 	"simple_p2p_client/account"
+	"simple_p2p_client/keystore"
 	"simple_p2p_client/leveldb"
+	"simple_p2p_client/utils"
 
 	db "github.com/syndtr/goleveldb/leveldb"
 )
 
 var NodeAccount string // 프로그램을 실행하는 노드의 주소
 
-// 노드 계정을 초기화하는 함수
-func InitializeNodeAccount() error {
+// 노드 계정을 초기화하는 함수 : 키는 키 저장소에 password로 암호화해 보관
+func InitializeNodeAccount(password string) error {
+	ks := keystore.GetKeyStore()
+	if ks == nil {
+		return fmt.Errorf("keystore is not initialized")
+	}
+
 	// DB 접근
 	dbInstance, err := leveldb.GetDBInstance()
 	if err != nil {
@@ -27,11 +34,14 @@ func InitializeNodeAccount() error {
 		// 기존 계정 있으면 불러오기
 		NodeAccount = string(accountData)
 		fmt.Printf("[ACCOUNT] Node Account load complete : %s\n", NodeAccount)
+		if !ks.HasAccount(NodeAccount) {
+			utils.PrintError(fmt.Sprintf("[ACCOUNT] Key of node account %s is not in the keystore, rewards cannot be spent", NodeAccount))
+		}
 		return nil
 	}
 
-	// 없으면 생성
-	_, address, err := account.CreateAccount()
+	// 없으면 키 저장소에 생성 (personal.Unlock 후 보상을 사용할 수 있음)
+	address, err := ks.NewAccount(password)
 	if err != nil {
 		return fmt.Errorf("노드 게정 생성 실패 : %v", err)
 	}
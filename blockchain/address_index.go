@@ -0,0 +1,213 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"simple_p2p_client/leveldb"
+
+	db "github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// 주소별 트랜잭션 인덱스
+// addrtx:<주소(소문자)>:<블록 번호>:<트랜잭션 인덱스> : 트랜잭션 해시 (보낸 트랜잭션, 받은 트랜잭션 모두)
+// addrtx:indexed : 기존 블록의 인덱스를 모두 만들었는지 (인덱스가 없던 예전 체인은 시작 시 한 번 생성)
+const addressIndexDoneKey = "addrtx:indexed"
+
+// 한 번에 조회할 수 있는 최대 트랜잭션 수
+const (
+	DefaultAddressTxLimit = 50
+	MaxAddressTxLimit     = 1000
+)
+
+// 주소의 트랜잭션 하나
+type AddressTx struct {
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   string      `json:"blockHash"`
+	Index       int         `json:"index"`
+	Timestamp   uint64      `json:"timestamp"`
+	Direction   string      `json:"direction"` // out (보냄), in (받음), self (자신에게)
+	Transaction Transaction `json:"transaction"`
+}
+
+// 블록 번호, 인덱스는 자릿수를 채워 키 순서와 블록 순서를 맞춤
+func addressTxKey(address string, number uint64, index int) []byte {
+	return []byte(fmt.Sprintf("addrtx:%s:%020d:%06d", strings.ToLower(address), number, index))
+}
+
+func addressTxPrefix(address string) []byte {
+	return []byte("addrtx:" + strings.ToLower(address) + ":")
+}
+
+// 블록 트랜잭션의 from, to 주소 인덱스를 배치에 추가
+func putAddressIndexes(batch *db.Batch, block *Block) {
+	for i, tx := range block.Transaction {
+		batch.Put(addressTxKey(tx.From, block.Number, i), []byte(tx.Hash))
+		if !strings.EqualFold(tx.From, tx.To) {
+			batch.Put(addressTxKey(tx.To, block.Number, i), []byte(tx.Hash))
+		}
+	}
+}
+
+// 인덱스가 없던 체인이면 기존 블록의 주소 인덱스 생성 (스냅샷 이전 등 DB에 없는 블록은 건너뜀)
+func initAddressIndex(dbInstance *db.DB) error {
+	if _, err := dbInstance.Get([]byte(addressIndexDoneKey), nil); err == nil {
+		return nil
+	} else if !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+
+	head, err := GetLatestBlock()
+	if err != nil {
+		return err
+	}
+
+	indexed := 0
+	batch := new(db.Batch)
+	for number := uint64(1); number <= head.Number; number++ {
+		block, err := GetBlockByNumber(number)
+		if errors.Is(err, db.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read block %d : %v", number, err)
+		}
+		putAddressIndexes(batch, block)
+		indexed += len(block.Transaction)
+
+		if batch.Len() >= 1000 {
+			if err := dbInstance.Write(batch, nil); err != nil {
+				return fmt.Errorf("failed to write address index : %v", err)
+			}
+			batch.Reset()
+		}
+	}
+	batch.Put([]byte(addressIndexDoneKey), []byte(strconv.FormatUint(head.Number, 10)))
+	if err := dbInstance.Write(batch, nil); err != nil {
+		return fmt.Errorf("failed to write address index : %v", err)
+	}
+	if indexed > 0 {
+		fmt.Printf("[BLOCK] Indexed %d transactions by address up to block %d\n", indexed, head.Number)
+	}
+	return nil
+}
+
+// 주소 트랜잭션 조회 커서 : 마지막으로 반환한 트랜잭션의 "<블록 번호>:<인덱스>"
+func parseAddressTxCursor(cursor string) (uint64, int, error) {
+	parts := strings.Split(cursor, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	number, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	index, err := strconv.Atoi(parts[1])
+	if err != nil || index < 0 {
+		return 0, 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return number, index, nil
+}
+
+// fromBlock~toBlock(0이면 마지막 블록까지) 사이에서 주소가 보내거나 받은 트랜잭션을 최신 순으로 최대 limit개 조회
+// 다음 페이지가 있으면 다음 호출에 넘길 커서 반환 (없으면 빈 문자열)
+// 본문을 정리한 블록은 인덱스도 없으므로 범위를 지정해 정리된 블록을 조회하면 에러 (0이면 남아 있는 블록부터)
+func GetAddressTransactions(address string, fromBlock, toBlock uint64, limit int, cursor string) ([]AddressTx, string, error) {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get dbinstance : %v", err)
+	}
+	tail, err := pruneTail(dbInstance)
+	if err != nil {
+		return nil, "", err
+	}
+	if (fromBlock != 0 && fromBlock < tail) || (toBlock != 0 && toBlock < tail) {
+		return nil, "", fmt.Errorf("transactions before block %d have been pruned", tail)
+	}
+	return addressTransactions(dbInstance, address, fromBlock, toBlock, limit, cursor, GetBlockByNumber)
+}
+
+// 주소 인덱스를 최신 순으로 읽어 트랜잭션 조회 (블록은 loadBlock으로 읽음)
+func addressTransactions(dbInstance *db.DB, address string, fromBlock, toBlock uint64, limit int, cursor string, loadBlock func(uint64) (*Block, error)) ([]AddressTx, string, error) {
+	if limit <= 0 {
+		limit = DefaultAddressTxLimit
+	}
+	if limit > MaxAddressTxLimit {
+		return nil, "", fmt.Errorf("limit must not exceed %d", MaxAddressTxLimit)
+	}
+	if toBlock != 0 && fromBlock > toBlock {
+		return nil, "", fmt.Errorf("fromBlock %d is after toBlock %d", fromBlock, toBlock)
+	}
+
+	// 범위 끝 (커서가 있으면 커서 위치 직전까지)
+	keyRange := &util.Range{Start: addressTxKey(address, fromBlock, 0)}
+	if toBlock != 0 {
+		keyRange.Limit = addressTxKey(address, toBlock+1, 0)
+	} else {
+		keyRange.Limit = util.BytesPrefix(addressTxPrefix(address)).Limit
+	}
+	if cursor != "" {
+		number, index, err := parseAddressTxCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if cursorKey := addressTxKey(address, number, index); string(cursorKey) < string(keyRange.Limit) {
+			keyRange.Limit = cursorKey
+		}
+	}
+
+	iter := dbInstance.NewIterator(keyRange, nil)
+	defer iter.Release()
+
+	result := []AddressTx{}
+	blocks := make(map[uint64]*Block)
+	for ok := iter.Last(); ok; ok = iter.Prev() {
+		if len(result) == limit {
+			last := result[len(result)-1]
+			return result, fmt.Sprintf("%d:%d", last.BlockNumber, last.Index), nil
+		}
+
+		// addrtx:<주소>:<블록 번호>:<인덱스>
+		parts := strings.Split(string(iter.Key()), ":")
+		number, index, err := parseAddressTxCursor(parts[len(parts)-2] + ":" + parts[len(parts)-1])
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid address index key %s", iter.Key())
+		}
+
+		block, cached := blocks[number]
+		if !cached {
+			block, err = loadBlock(number)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read block %d : %v", number, err)
+			}
+			blocks[number] = block
+		}
+		if index >= len(block.Transaction) || block.Transaction[index].Hash != string(iter.Value()) {
+			return nil, "", fmt.Errorf("address index points to a missing transaction in block %d", number)
+		}
+
+		tx := block.Transaction[index]
+		direction := "in"
+		switch {
+		case strings.EqualFold(tx.From, tx.To):
+			direction = "self"
+		case strings.EqualFold(tx.From, address):
+			direction = "out"
+		}
+		result = append(result, AddressTx{
+			BlockNumber: number,
+			BlockHash:   block.Hash,
+			Index:       index,
+			Timestamp:   block.Timestamp,
+			Direction:   direction,
+			Transaction: tx,
+		})
+	}
+	if err := iter.Error(); err != nil {
+		return nil, "", err
+	}
+	return result, "", nil
+}
@@ -3,8 +3,8 @@ package blockchain
 import (
 	"encoding/json"
 	"fmt"
-	"math/big"
 	"simple_p2p_client/account"
+	"simple_p2p_client/constants"
 	"simple_p2p_client/leveldb"
 	"simple_p2p_client/utils"
 	"strings"
@@ -21,70 +21,43 @@ type Block struct {
 	MerkleRoot  string        `json:"merkleRoot"`
 	Transaction []Transaction `json:"transaction"`
 	Miner       string        `json:"miner"`
+	Coinbase    *Coinbase     `json:"coinbase,omitempty"`   // 블록 보상, 수수료 내역 (제네시스는 없음)
+	ConfigHash  string        `json:"configHash,omitempty"` // 제네시스 설정 해시 (제네시스만, 설정 해시 도입 이전 체인은 없음)
 }
 
-// 이전 블록을 참고하여, Hash가 현재 블록의 ParentHash와 같은지 비교, timestamp는 더 큰지 비교
+// 마지막 블록을 불러와 새 블록의 번호, 부모 해시, 타임스탬프 검증
 func ValidateBlockWithPrevBlock(newBlock *Block) error {
-	dbInstance, err := leveldb.GetDBInstance()
-	if err != nil {
-		utils.PrintError(fmt.Sprintf("Failed to get dbinstance: %v", err))
-
-	}
-	lastblockJSON, err := leveldb.GetLastBlock(dbInstance)
+	lastBlock, err := GetLatestBlock()
 	if err != nil {
-		utils.PrintError(fmt.Sprintf("Failed to get lastblock: %v", err))
-
-	}
-
-	// json 데이터를 블록 구조체로
-	var lastBlock Block
-	json.Unmarshal(lastblockJSON, &lastBlock)
-
-	// timestamp 비교
-	// 이전 블록보다 시간이 큰지
-	if newBlock.Timestamp <= lastBlock.Timestamp {
-		return fmt.Errorf("invalid block: timestamp (%d) is not greater than or equal to the previous block's timestamp (%d)", newBlock.Timestamp, lastBlock.Timestamp)
-	}
-
-	// 새 블록과 이전 블록의 해시값 비교
-
-	if lastBlock.Hash == newBlock.ParentHash {
-		return nil
-	} else {
-		return fmt.Errorf("invalid block : parent hash : %s , last block hash : %s", newBlock.ParentHash, lastBlock.Hash)
+		return fmt.Errorf("failed to get lastblock : %v", err)
 	}
 
+	return validateBlockHeader(newBlock, lastBlock, clock.Now())
 }
 
-// 블록 구조체를 DB에 저장
-func StoreBlock(block *Block) error {
-	dbInstance, err := leveldb.GetDBInstance()
-	if err != nil {
-		utils.PrintError(fmt.Sprintf("Failed to get dbinstance: %v", err))
+// 부모 블록 기준 헤더 규칙
+//   - 번호는 부모 + 1, 부모 해시 일치
+//   - 타임스탬프는 부모 + MinBlockSpacing 이상, 로컬 시각 + MaxBlockFutureDrift 이하
+func validateBlockHeader(newBlock, parent *Block, now time.Time) error {
+	if newBlock.Number != parent.Number+1 {
+		return fmt.Errorf("invalid block : number is %d, expected %d", newBlock.Number, parent.Number+1)
 	}
 
-	// 블록 구조체 json 직렬화
-	blockJSON, err := json.Marshal(block)
-	if err != nil {
-		utils.PrintError(fmt.Sprintf("Failed to marshal block: %v", err))
+	if newBlock.ParentHash != parent.Hash {
+		return fmt.Errorf("invalid block : parent hash : %s , last block hash : %s", newBlock.ParentHash, parent.Hash)
 	}
 
-	// 3. Batch 작업 생성
-	batch := new(db.Batch)
-
-	// 4, Batch 작업 추가
-	batch.Put([]byte(block.Hash), blockJSON)
-	batch.Put([]byte("lastblock"), blockJSON)
+	minTimestamp := parent.Timestamp + uint64(constants.MinBlockSpacing/time.Second)
+	if newBlock.Timestamp < minTimestamp {
+		return fmt.Errorf("invalid block : timestamp (%d) must be at least %d (parent %d + %v)", newBlock.Timestamp, minTimestamp, parent.Timestamp, constants.MinBlockSpacing)
+	}
 
-	// 5. Batch 실행
-	dbInstance.Write(batch, nil)
-	if err != nil {
-		return fmt.Errorf("failed to write batch to LevelDB: %w", err)
+	maxTimestamp := uint64(now.Add(constants.MaxBlockFutureDrift).Unix())
+	if newBlock.Timestamp > maxTimestamp {
+		return fmt.Errorf("invalid block : timestamp (%d) is too far in the future (local time %d, allowed drift %v)", newBlock.Timestamp, now.Unix(), constants.MaxBlockFutureDrift)
 	}
 
-	fmt.Printf("[BLOCK] Stored, Number : %v, Hash : %v\n", block.Number, block.Hash)
 	return nil
-
 }
 
 // 트랜잭션들을 모아 블록 구조체 생성
@@ -132,21 +105,21 @@ func CreateNewBlock(transactions []Transaction) *Block {
 	newBlock := &Block{
 		Number:      lastBlock.Number + 1,
 		ParentHash:  lastBlock.Hash,
-		Timestamp:   uint64(time.Now().Unix()),
+		Timestamp:   uint64(clock.Now().Unix()),
 		Transaction: transactions,
 		Miner:       NodeAccount, // 프로그램을 실행하는 노드의 주소
 		MerkleRoot:  merkleRoot,
+		Coinbase:    chainConfig.Reward.newCoinbase(lastBlock.Number+1, transactions),
 	}
 
 	// 5. 블록 해시 계산
-	blockHashData := fmt.Sprintf("%d%s%s%s%d", newBlock.Number, newBlock.ParentHash, merkleRoot, newBlock.Miner, newBlock.Timestamp)
-	newBlock.Hash = utils.BytesToHex(utils.Keccak256([]byte(blockHashData)))
+	newBlock.Hash = computeBlockHash(newBlock)
 
 	return newBlock
 }
 
 // 블록 유효성 검증
-func validateReceivedBlock(block *Block) error {
+func validateReceivedBlock(block *Block, overlay *stateOverlay) error {
 	fmt.Println("[BLOCK] Starting Validation...")
 
 	// 1. 이전 블록 해시 검증
@@ -158,16 +131,59 @@ func validateReceivedBlock(block *Block) error {
 	fmt.Println("[BLOCK] Validated against the Previous block")
 
 	// 2. 현재 블록 해시 검증
-	blockHashData := fmt.Sprintf("%d%s%s%s%d", block.Number, block.ParentHash, block.MerkleRoot, block.Miner, block.Timestamp)
-	expectedHash := utils.BytesToHex(utils.Keccak256([]byte(blockHashData)))
-	if block.Hash != expectedHash {
-		// TODO : 상대방에게 알리기
-		return fmt.Errorf("invalid block hash : expected : %s, got %s", expectedHash, block.Hash)
+	// TODO : 상대방에게 알리기
+	if err := verifyBlockHash(block); err != nil {
+		return err
 	}
 
 	fmt.Println("[BLOCK] Validated the hash of this block")
 
 	// 3. 머클루트 검증
+	if err := verifyMerkleRoot(block); err != nil {
+		return err
+	}
+
+	fmt.Println("[BLOCK] Validated the merkleroot of this block")
+
+	fmt.Println("[BLOCK] Starting validation of transactions in this block...")
+
+	// 4. 보상 내역 검증
+	err = chainConfig.Reward.validateCoinbase(block)
+	if err != nil {
+		return err
+	}
+
+	// 5. 블록 내 트랜잭션을 메모리 상태에서 순서대로 실행하며 검증 (nonce 순서, 잔액, 중복, 개수)
+	err = validateBlockTransactions(block, overlay)
+	if err != nil {
+		return fmt.Errorf("transaction validation failed : %v", err)
+	}
+
+	return nil
+}
+
+// 블록 해시 : 헤더(번호, 부모 해시, 머클루트, miner, 타임스탬프)의 Keccak256
+// 제네시스는 설정 해시도 포함하므로 설정(초기 발행량, 보상 규칙)이 다른 체인은 제네시스 해시가 다름
+// (설정 해시가 없는 예전 제네시스는 기존 방식 그대로 계산)
+func computeBlockHash(block *Block) string {
+	blockHashData := fmt.Sprintf("%d%s%s%s%d", block.Number, block.ParentHash, block.MerkleRoot, block.Miner, block.Timestamp)
+	if block.ConfigHash != "" {
+		blockHashData += block.ConfigHash
+	}
+	return utils.BytesToHex(utils.Keccak256([]byte(blockHashData)))
+}
+
+// 블록 헤더로 계산한 해시와 블록 해시 비교
+func verifyBlockHash(block *Block) error {
+	expectedHash := computeBlockHash(block)
+	if block.Hash != expectedHash {
+		return fmt.Errorf("invalid block hash : expected : %s, got %s", expectedHash, block.Hash)
+	}
+	return nil
+}
+
+// 트랜잭션 해시로 계산한 머클루트와 블록 머클루트 비교
+func verifyMerkleRoot(block *Block) error {
 	var transactionHashes []string
 	for _, tx := range block.Transaction {
 		txHash := strings.TrimPrefix(tx.Hash, "0x") // 0x 제거
@@ -180,65 +196,188 @@ func validateReceivedBlock(block *Block) error {
 	if block.MerkleRoot != calculatedMerkleRoot {
 		return fmt.Errorf("invalid Merkle root : expected %s, got %s", calculatedMerkleRoot, block.MerkleRoot)
 	}
+	return nil
+}
 
-	fmt.Println("[BLOCK] Validated the merkleroot of this block")
+// 블록 저장과 실행을 한 배치로 기록 : 블록, 인덱스, lastblock, 트랜잭션 실행 결과(from 차감, to 입금, miner 수수료), miner 보상, 총 발행량
+// overlay는 validateBlockTransactions로 블록 트랜잭션을 검증하며 실행한 메모리 상태 (중간에 실패하면 아무것도 바뀌지 않음)
+func commitBlock(block *Block, overlay *stateOverlay) error {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return fmt.Errorf("failed to get dbinstance : %v", err)
+	}
+	if !account.IsValidAddress(block.Miner) {
+		return fmt.Errorf("invalid miner address : %s", block.Miner)
+	}
 
-	fmt.Println("[BLOCK] Starting validation of transactions in this block...")
+	// 1. Miner 보상 (트랜잭션 실행 후), 총 발행량에 보상 발행과 수수료 소각 반영
+	coinbase := block.Coinbase
+	if coinbase == nil {
+		coinbase = overlay.reward.newCoinbase(block.Number, block.Transaction)
+	}
+	miner, err := overlay.get(block.Miner)
+	if err != nil {
+		return fmt.Errorf("failed to load miner account %s : %v", block.Miner, err)
+	}
+	miner.Balance.Add(miner.Balance, coinbase.Reward)
+
+	supply, err := GetTotalSupply()
+	if err != nil {
+		return err
+	}
+	supply.Add(supply, coinbase.Reward)
+	supply.Sub(supply, coinbase.Burned)
+
+	// 2. 블록, 인덱스, 바뀐 계정, 총 발행량을 한 배치로 저장
+	blockJSON, err := json.Marshal(block)
+	if err != nil {
+		return fmt.Errorf("failed to marshal block : %v", err)
+	}
+	batch := new(db.Batch)
+	batch.Put([]byte(block.Hash), blockJSON)
+	batch.Put([]byte("lastblock"), blockJSON)
+	if err := putBlockIndexes(batch, block); err != nil {
+		return err
+	}
+	if err := overlay.putAccounts(batch); err != nil {
+		return err
+	}
+	batch.Put([]byte(totalSupplyKey), []byte(supply.String()))
+	if err := dbInstance.Write(batch, nil); err != nil {
+		return fmt.Errorf("failed to write batch to LevelDB: %w", err)
+	}
 
-	// 4. 블록 내 트랜잭션 검증
+	fmt.Printf("[BLOCK] Stored, Number : %v, Hash : %v\n", block.Number, block.Hash)
 	for _, tx := range block.Transaction {
+		fmt.Printf("[TX] Execution completed, Hash : %s, From : %s, To: %s, Value : %s, Fee : %s\n", tx.Hash, tx.From, tx.To, tx.Value, tx.FeeOrZero())
+	}
+	fmt.Printf("[BLOCK CREATOR] Rewarded %s to miner : %s, burned fees : %s\n", coinbase.Reward, block.Miner, coinbase.Burned)
+	return nil
+}
 
-		_, err := ProcessTransactionFromBlock(tx)
+// 블록 조회용 인덱스 : 트랜잭션 해시로 블록 위치를 찾기 위한 값
+type TxLookup struct {
+	BlockHash   string `json:"blockHash"`
+	BlockNumber uint64 `json:"blockNumber"`
+	Index       int    `json:"index"`
+}
+
+// 블록 번호 => 해시 키
+func blockNumberKey(number uint64) []byte {
+	return []byte(fmt.Sprintf("block:%d", number))
+}
+
+// 트랜잭션 해시 => TxLookup 키
+func txLookupKey(txHash string) []byte {
+	return []byte("tx:" + strings.ToLower(txHash))
+}
+
+// 블록 번호, 트랜잭션 위치, 주소별 트랜잭션 인덱스를 배치에 추가
+func putBlockIndexes(batch *db.Batch, block *Block) error {
+	batch.Put(blockNumberKey(block.Number), []byte(block.Hash))
+
+	for i, tx := range block.Transaction {
+		lookupJSON, err := json.Marshal(TxLookup{BlockHash: block.Hash, BlockNumber: block.Number, Index: i})
 		if err != nil {
-			return fmt.Errorf("transaction validation failed for tx %s: %v", tx.Hash, err)
+			return fmt.Errorf("failed to marshal tx lookup : %v", err)
 		}
+		batch.Put(txLookupKey(tx.Hash), lookupJSON)
+	}
+	putAddressIndexes(batch, block)
+	return nil
+}
 
+// 가장 최근 블록 조회
+func GetLatestBlock() (*Block, error) {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dbinstance : %v", err)
+	}
+	lastblockJSON, err := leveldb.GetLastBlock(dbInstance)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	var lastBlock Block
+	if err := json.Unmarshal(lastblockJSON, &lastBlock); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal last block : %v", err)
+	}
+	return &lastBlock, nil
 }
 
-// 블록 Miner에게 보상 지급
-func RewardToMiner(minerAddress string) error {
+// 블록 해시(0x 유무 무관)로 블록 조회
+func GetBlockByHash(hash string) (*Block, error) {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dbinstance : %v", err)
+	}
 
-	const rewardAmount = 1000
-	rewardBigInt := big.NewInt(rewardAmount)
+	blockJSON, err := dbInstance.Get([]byte(strings.TrimPrefix(hash, "0x")), nil)
+	if err != nil {
+		return nil, fmt.Errorf("block not found : %s : %w", hash, err)
+	}
 
-	// 주소 유효성 검증
-	if !account.IsValidAddress(minerAddress) {
-		return fmt.Errorf("invalid miner address : %s", minerAddress)
+	var block Block
+	if err := json.Unmarshal(blockJSON, &block); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block : %v", err)
 	}
+	return &block, nil
+}
 
-	// Miner 계정 존재하는지 확인
-	minerExists, err := account.AccountExists(minerAddress)
+// 블록 번호로 블록 조회, 번호 인덱스가 없는 예전 블록은 lastblock부터 부모를 따라가며 찾음
+func GetBlockByNumber(number uint64) (*Block, error) {
+	dbInstance, err := leveldb.GetDBInstance()
 	if err != nil {
-		return fmt.Errorf("failed to check miner account existence : %v", err)
+		return nil, fmt.Errorf("failed to get dbinstance : %v", err)
+	}
+
+	hash, err := dbInstance.Get(blockNumberKey(number), nil)
+	if err == nil {
+		return GetBlockByHash(string(hash))
+	}
+	if err != db.ErrNotFound {
+		return nil, fmt.Errorf("failed to read block number index : %v", err)
 	}
 
-	// 없는 계정이면 db에 저장
-	if !minerExists {
-		_, err := account.StoreAccount(minerAddress)
+	block, err := GetLatestBlock()
+	if err != nil {
+		return nil, err
+	}
+	if number > block.Number {
+		return nil, fmt.Errorf("block not found : number %d : %w", number, db.ErrNotFound)
+	}
+	for block.Number > number {
+		block, err = GetBlockByHash(block.ParentHash)
 		if err != nil {
-			return fmt.Errorf("failed to create miner account : %v", err)
+			return nil, err
 		}
 	}
+	return block, nil
+}
 
-	// 계정 불러오기
+// 트랜잭션 해시로 블록에 포함된 트랜잭션과 위치 조회
+func GetTransactionByHash(txHash string) (*Transaction, *TxLookup, error) {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get dbinstance : %v", err)
+	}
 
-	minerAccount, err := account.GetAccount(minerAddress)
+	lookupJSON, err := dbInstance.Get(txLookupKey(txHash), nil)
 	if err != nil {
-		return fmt.Errorf("failed to retrieve miner account : %v", err)
+		return nil, nil, fmt.Errorf("transaction not found : %s : %w", txHash, err)
 	}
 
-	// 잔액 업데이트
-	minerAccount.Balance.Add(minerAccount.Balance, rewardBigInt)
+	var lookup TxLookup
+	if err := json.Unmarshal(lookupJSON, &lookup); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal tx lookup : %v", err)
+	}
 
-	// 업데이트 된 계정 저장
-	err = account.UpdateAccount(minerAddress, minerAccount)
+	block, err := GetBlockByHash(lookup.BlockHash)
 	if err != nil {
-		return fmt.Errorf("failed to update miner account: %v", err)
+		return nil, nil, err
 	}
-
-	fmt.Printf("[BLOCK CREATOR] Rewarded %d to miner : %s\n", rewardAmount, minerAddress)
-	return nil
+	if lookup.Index >= len(block.Transaction) {
+		return nil, nil, fmt.Errorf("tx lookup index out of range : %s", txHash)
+	}
+	return &block.Transaction[lookup.Index], &lookup, nil
 }
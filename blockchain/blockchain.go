@@ -11,9 +11,8 @@ import (
 	"simple_p2p_client/constants"
 	"simple_p2p_client/leveldb"
 	"simple_p2p_client/mediator"
-	"simple_p2p_client/protocol_constants"
-
 	"simple_p2p_client/utils"
+	"sync"
 	"time"
 )
 
@@ -30,28 +29,46 @@ func InitGenesisBlock() error {
 	// 이미 블록이 있는지 확인
 	lastBlockData, err := dbInstance.Get([]byte("lastblock"), nil)
 	if err == nil && len(lastBlockData) > 0 {
-		// lastblock이 있다면 패스
+		// lastblock이 있다면 패스, 체인을 만들 때 저장한 제네시스 설정 사용
+		chainConfig, err = loadStoredGenesisConfig(dbInstance)
+		if err != nil {
+			return fmt.Errorf("failed to load genesis config: %v", err)
+		}
 		fmt.Println("[BLOCK] already initialized, Skipping genesis block creation...")
-		return nil
+		if err := initAddressIndex(dbInstance); err != nil {
+			return fmt.Errorf("failed to build address index: %v", err)
+		}
+		return initStateHistory(dbInstance)
+	}
+
+	// 제네시스 설정 (파일 또는 기본값)
+	chainConfig, err = resolveGenesisConfig()
+	if err != nil {
+		return err
+	}
+
+	configHash, err := chainConfig.Hash()
+	if err != nil {
+		return err
 	}
 
 	// 제네시스 블록 생성
 	genesisBlock := Block{
 		Number:      1,
-		Hash:        "",                                           // 아래에서 계산
-		ParentHash:  "0x0",                                        // 부모 해시는 없음
-		Timestamp:   0,                                            // 0으로 고정
-		MerkleRoot:  "0x0",                                        // 트랜잭션이 없으므로 Merkle Root는 0x0
-		Transaction: []Transaction{},                              // 빈 트랜잭션 리스트
-		Miner:       "0xde589C867174C349d00e9b582867aF5c13A74679", // Test 주소 생성 및 코인 입금
+		Hash:        "",                // 아래에서 계산
+		ParentHash:  "0x0",             // 부모 해시는 없음
+		Timestamp:   0,                 // 0으로 고정
+		MerkleRoot:  "0x0",             // 트랜잭션이 없으므로 Merkle Root는 0x0
+		Transaction: []Transaction{},   // 빈 트랜잭션 리스트
+		Miner:       chainConfig.Miner, // 초기 발행량을 받는 주소
+		ConfigHash:  configHash,        // 설정이 다른 체인과 제네시스 해시가 달라지도록 설정 해시 포함
 	}
 
 	// 블록 해시 계산
-	blockHashData := fmt.Sprintf("%d%s%s%s%d", genesisBlock.Number, genesisBlock.ParentHash, genesisBlock.MerkleRoot, genesisBlock.Miner, genesisBlock.Timestamp)
-	genesisBlock.Hash = utils.BytesToHex(utils.Keccak256([]byte(blockHashData)))
+	genesisBlock.Hash = computeBlockHash(&genesisBlock)
 
 	// Genesis Miner 계정 생성 및 초기 코인 입금
-	initialBalance := big.NewInt(10000)
+	initialBalance := new(big.Int).Set(chainConfig.Balance)
 	success, err := account.StoreAccountForGenesisMiner(genesisBlock.Miner, initialBalance)
 	if err != nil || !success {
 		return fmt.Errorf("failed to init miner account: %v", err)
@@ -68,6 +85,15 @@ func InitGenesisBlock() error {
 	batch := new(db.Batch)
 	batch.Put([]byte(genesisBlock.Hash), blockJSON)
 	batch.Put([]byte("lastblock"), blockJSON)
+	batch.Put(blockNumberKey(genesisBlock.Number), []byte(genesisBlock.Hash))
+
+	// 제네시스 설정, 총 발행량 저장
+	configJSON, err := json.Marshal(chainConfig)
+	if err != nil {
+		return fmt.Errorf("failed to serialize genesis config: %v", err)
+	}
+	batch.Put([]byte(genesisConfigKey), configJSON)
+	batch.Put([]byte(totalSupplyKey), []byte(initialBalance.String()))
 
 	err = dbInstance.Write(batch, nil)
 	if err != nil {
@@ -77,115 +103,139 @@ func InitGenesisBlock() error {
 
 	fmt.Printf("[BLOCK] Genesis block created, hash: %s\n", genesisBlock.Hash)
 
-	return nil
+	if err := initAddressIndex(dbInstance); err != nil {
+		return fmt.Errorf("failed to build address index: %v", err)
+	}
+	return initStateHistory(dbInstance)
 }
 
-// p2p, rpc에서 blockchain으로 보내는 채널의 메시지를 수신해 트랜잭션, 블록을 검증하고 전파
+// p2p, rpc에서 발행한 트랜잭션, 블록 이벤트를 구독해 검증하고 결과를 발행
 func StartBlockchainProcessor() {
 	mediatorInstance := mediator.GetMediatorInstance()
+	sub := mediatorInstance.Subscribe(100, mediator.PolicyBlock, mediator.NewTxEventType, mediator.NewBlockEventType)
 
 	go func() {
-		for message := range mediatorInstance.P2PToBlockchain {
+		for event := range sub.Events() {
 
-			if len(message) == 0 {
-				fmt.Println("Received empty message, skipping...")
-				continue
-			}
-
-			// 첫 바이트로 메시지 타입 판별
-			messageBytes := []byte(message)
-			messageType := messageBytes[0]
-			messageContent := string(messageBytes[1:]) // 프로토콜 ID 제거 및 트림 처리
-
-			switch messageType {
+			switch ev := event.(type) {
 
-			// 0x01 : 피어로부터, rpc서버로 부터 트랜잭션을 받았을 때
-			case protocol_constants.P2PTransactionMessage:
-				fmt.Printf("[TX] Received Transaction, Processing... : %s\n", messageContent)
+			// 피어로부터, rpc서버로 부터 트랜잭션을 받았을 때
+			case mediator.NewTxEvent:
+				fmt.Printf("[TX] Received Transaction, Processing... : %s\n", ev.Payload)
 				// 트랜잭션 처리 (검증 및 멤풀 저장)
-				processedMessage, err := ProcessTransaction(messageContent)
+				txHash, processedMessage, err := ProcessTransaction(ev.Payload)
 				if err != nil {
 					fmt.Printf("[TX] Validation failed : %v\n", err)
-					continue
 				}
-				
-				// Blockchain => p2p 전달
-				mediatorInstance.BlockchainToP2P <- fmt.Sprintf("%c%s", 0x01, processedMessage)
 
-			// 0x02 : 블록을 다른 피어로부터 받았을 때
-			case protocol_constants.P2PBlockMessage:
-				fmt.Printf("[BLOCK] Recevied Block, Processing... : %s\n", messageContent)
+				// 검증 결과 발행 (rpc는 응답, p2p는 성공 시 전파)
+				mediatorInstance.Publish(mediator.TxValidationResult{
+					Source:  ev.Source,
+					TxHash:  txHash,
+					Payload: processedMessage,
+					Err:     err,
+				})
+
+			// 블록을 다른 피어로부터 받았을 때
+			case mediator.NewBlockEvent:
+				fmt.Printf("[BLOCK] Recevied Block, Processing... : %s\n", ev.Payload)
 
 				// 1. json -> Block 구조체 변환
 				var receivedBlock Block
-				err := json.Unmarshal([]byte(messageContent), &receivedBlock)
+				err := json.Unmarshal([]byte(ev.Payload), &receivedBlock)
 				if err != nil {
 					fmt.Printf("Failed to parse block : %v\n", err)
 					continue
 				}
 
-				// 2. 블록 검증
-				err = validateReceivedBlock(&receivedBlock)
-				if err != nil {
-					fmt.Printf("[BLOCK] Validation failed : %v\n", err)
-					continue
-				}
-
-				fmt.Println("[BLOCK] Validation completes!")
-
-				// Miner 보상 지금
-				err = RewardToMiner(receivedBlock.Miner)
-				if err != nil {
-					fmt.Printf("Failed to reward miner : %v\n", err)
-				}
-
-				// 3. 블록 저장
-				err = StoreBlock(&receivedBlock)
-				if err != nil {
-					fmt.Printf("Failed to store block :%v\n", err)
+				// 2. 블록 검증, 저장, 트랜잭션 실행, 보상 지급
+				if err := ImportBlock(&receivedBlock); err != nil {
+					fmt.Printf("[BLOCK] %v\n", err)
 					continue
 				}
-				fmt.Printf("[BLOCK] Validated and Stored : %s\n", receivedBlock.Hash)
-
-				// 4. 트랜잭션 실행
-				err = ExecuteTransactions(receivedBlock.Transaction)
-				if err != nil {
-					fmt.Printf("Failed to execute transaction: %v\n", err)
-					// TODO : 트랜잭션이 실패될 경우 블록 저장을 어떻게 롤백할 것인가
-				}
-				fmt.Println("[TX] Execution transactions in this block completed")
 
-				// 5. 멤풀에서 이미 처리한 트랜잭션 제거
+				// 3. 멤풀에서 이미 처리한 트랜잭션 제거
 				defaultMempool.CleanMempoolAfterReceiveBlock(receivedBlock.Transaction)
+				defaultMempool.Revalidate()
 				fmt.Println("[Mempool] Cleaned after processing block")
 
-				// 6. 블록을 채널에 전달 (피어에게 전파)
+				// 4. 새 헤드 이벤트 발행 (피어에게 전파)
 				blockJSON, err := json.Marshal(receivedBlock)
 				if err != nil {
 					fmt.Printf("Failed to serialize block to JSON : %v\n", err)
 					continue
 				}
-				mediatorInstance := mediator.GetMediatorInstance()
-				message := fmt.Sprintf("%c%s", protocol_constants.P2PBlockMessage, string(blockJSON))
-				mediatorInstance.P2PToBlockchain <- message
-
-			default: // 알수 없는 메시지 타입
-				fmt.Printf("[MESSAGE] Recevied from peer : %s\n", messageContent)
+				publishNewHead(&receivedBlock, blockJSON)
 			}
 
 		}
 	}()
 }
 
+// 블록 저장부터 보상 지급까지를 한 번에 하나만 실행 (블록 생성, 받은 블록 처리, 스냅샷 생성)
+var chainMu sync.Mutex
+
+// 검증을 통과한 블록을 저장하고 실행 (피어에게 받은 블록, 파일에서 가져온 블록)
+func ImportBlock(block *Block) error {
+	chainMu.Lock()
+	defer chainMu.Unlock()
+
+	// 1. 블록 검증 (트랜잭션은 메모리 상태에 실행)
+	overlay := newStateOverlay()
+	if err := validateReceivedBlock(block, overlay); err != nil {
+		return fmt.Errorf("validation failed : %v", err)
+	}
+	fmt.Println("[BLOCK] Validation completes!")
+
+	// 2. 블록 저장, 트랜잭션 실행 결과, Miner 보상 지급 (한 배치로 저장)
+	if err := commitBlock(block, overlay); err != nil {
+		return fmt.Errorf("failed to commit block : %v", err)
+	}
+	fmt.Printf("[BLOCK] Validated and Stored : %s\n", block.Hash)
+
+	// 3. 바뀐 계정 상태 이력 기록, 보관 범위를 벗어난 블록 본문 정리
+	// 블록은 이미 반영되었으므로 실패해도 가져오기 실패로 처리하지 않음 (블록 생성과 같이 로그만 남김)
+	if err := recordStateChanges(block); err != nil {
+		fmt.Printf("Failed to record state history : %v\n", err)
+	}
+	if err := pruneOldBlocks(block.Number); err != nil {
+		fmt.Printf("Failed to prune old blocks : %v\n", err)
+	}
+	return nil
+}
+
+// 블록 생성 주기, 블록 당 트랜잭션 개수 (설정 파일로 변경 가능)
+var (
+	blockCreationInterval = constants.BlockCreationInterval
+	transactionsPerBlock  = constants.TransactionsPerBlock
+)
+
+// 블록 생성 주기, 블록 당 트랜잭션 개수 설정 (노드 시작 시 설정으로 지정)
+func SetBlockCreation(interval time.Duration, txsPerBlock int) {
+	blockCreationInterval = interval
+	transactionsPerBlock = txsPerBlock
+}
+
 // 일정 주기로 멤풀을 확인하고 블록 생성 시도
 func StartBlockCreator() {
-	// 실행 주기는 constants에서 설정 가능
-	ticker := time.NewTicker(constants.BlockCreationInterval)
+	ticker := time.NewTicker(blockCreationInterval)
 	defer ticker.Stop()
 
 	for range ticker.C {
 		fmt.Println("[BLOCK CREATOR] Start trying to create block")
-		defaultMempool.SyncFutureToPending()
+
+		// 마지막 블록과의 최소 간격이 지나지 않았으면 다음 주기로 (받은 블록 직후 등)
+		lastBlock, err := GetLatestBlock()
+		if err != nil {
+			fmt.Printf("[BLOCK CREATOR] Failed to get last block : %v\n", err)
+			continue
+		}
+		if uint64(clock.Now().Unix()) < lastBlock.Timestamp+uint64(constants.MinBlockSpacing/time.Second) {
+			fmt.Println("[BLOCK CREATOR] Cancelled, too soon after the last block")
+			continue
+		}
+		// 현재 nonce, 잔액 기준으로 pending 정리, 이어지는 future 승격
+		defaultMempool.Revalidate()
 		fmt.Println("[Mempool] Sync complete")
 
 		defaultMempool.mu.Lock()
@@ -194,13 +244,13 @@ func StartBlockCreator() {
 			totalTransactions += len(accountTxs)
 		}
 
-		if totalTransactions < constants.TransactionsPerBlock {
+		if totalTransactions < transactionsPerBlock {
 			defaultMempool.mu.Unlock()
 			fmt.Println("[BLOCK CREATOR] Cancelled, Not enough transactions")
 			continue
 		}
 
-		blockTxs := defaultMempool.ExtractTransactionsForBlock(constants.TransactionsPerBlock)
+		blockTxs := defaultMempool.ExtractTransactionsForBlock(transactionsPerBlock)
 		fmt.Printf("[BLOCK CREATOR] Transactions extracted for block : %v\n", blockTxs)
 		defaultMempool.mu.Unlock()
 
@@ -209,51 +259,80 @@ func StartBlockCreator() {
 			continue
 		}
 
-		newBlock := CreateNewBlock(blockTxs)
-		if newBlock == nil {
-			fmt.Println("New block creation failed. Skipping block storage.")
-			continue
-		}
-		fmt.Printf("[BLOCK CREATOR] New Block created: %v\n", newBlock)
-
-		// JSON 직렬화 후 데이터 확인
-		blockJSON, err := json.Marshal(newBlock)
+		newBlock, blockJSON, err := commitNewBlock(blockTxs)
 		if err != nil {
-			fmt.Printf("Failed to serialize block to JSON: %v\n", err)
+			utils.PrintError(fmt.Sprintf("[BLOCK CREATOR] %v", err))
 			continue
 		}
 
-		// 블록 저장
-		err = StoreBlock(newBlock)
-		if err != nil {
-			utils.PrintError(fmt.Sprintf("Failed to store block: %v", err))
-			continue
-		}
-		fmt.Printf("[BLOCK CREATOR] New Block stored: %v\n", newBlock)
+		// 바뀐 nonce, 잔액으로 멤풀 재검증
+		defaultMempool.Revalidate()
 
-		// 블록 안 트랜잭션 실행
-		fmt.Printf("[BLOCK CREATOR] Transaction execution begins...\n")
+		// 새 헤드 이벤트 발행 (피어에게 전파)
+		fmt.Printf("[BLOCK CREATOR] Publishing new head to broadcast block to peers: %s\n", newBlock.Hash)
+		publishNewHead(newBlock, blockJSON)
+	}
 
-		err = ExecuteTransactions(newBlock.Transaction)
-		if err != nil {
-			fmt.Printf("Failed to execute transaction: %v\n", err)
-			// TODO : 트랜잭션이 실패될 경우 블록 저장을 어떻게 롤백할 것인가
-		}
-		fmt.Printf("[BLOCK CREATOR] Transactions executed : %v\n", newBlock.Transaction)
+}
 
-		// Miner 보상 지금
-		err = RewardToMiner(newBlock.Miner)
-		if err != nil {
-			fmt.Printf("Failed to reward miner : %v\n", err)
-		}
+// 멤풀에서 꺼낸 트랜잭션으로 블록을 만들어 저장, 실행, 보상 지급
+func commitNewBlock(blockTxs []Transaction) (*Block, []byte, error) {
+	chainMu.Lock()
+	defer chainMu.Unlock()
+
+	newBlock := CreateNewBlock(blockTxs)
+	if newBlock == nil {
+		return nil, nil, fmt.Errorf("new block creation failed, skipping block storage")
+	}
+	fmt.Printf("[BLOCK CREATOR] New Block created: %v\n", newBlock)
+
+	// JSON 직렬화 후 데이터 확인
+	blockJSON, err := json.Marshal(newBlock)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to serialize block to JSON: %v", err)
+	}
 
-		// 블록을 채널에 전달 (피어에게 전파)
+	// 저장하기 전에 받은 블록과 같은 방식으로 메모리 상태에서 트랜잭션 검증, 실행 (실패하면 저장, 전파하지 않음)
+	overlay := newStateOverlay()
+	if err := validateBlockTransactions(newBlock, overlay); err != nil {
+		return nil, nil, fmt.Errorf("transaction validation failed: %v", err)
+	}
 
-		mediatorInstance := mediator.GetMediatorInstance()
-		message := fmt.Sprintf("%c%s", protocol_constants.P2PBlockMessage, string(blockJSON))
-		fmt.Printf("[BLOCK CREATOR] Forwarding to broadcast block to peers: %s\n", message)
+	// 블록 저장, 트랜잭션 실행 결과, Miner 보상 지급 (한 배치로 저장)
+	if err := commitBlock(newBlock, overlay); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit block: %v", err)
+	}
+	fmt.Printf("[BLOCK CREATOR] New Block stored: %v\n", newBlock)
 
-		mediatorInstance.BlockchainToP2P <- message
+	if err := recordStateChanges(newBlock); err != nil {
+		fmt.Printf("Failed to record state history : %v\n", err)
 	}
+	if err := pruneOldBlocks(newBlock.Number); err != nil {
+		fmt.Printf("Failed to prune old blocks : %v\n", err)
+	}
+	return newBlock, blockJSON, nil
+}
 
+// 블록 저장, 실행 후 새 헤드와 변경된 계정(from, to, miner) 이벤트 발행
+func publishNewHead(block *Block, blockJSON []byte) {
+	mediatorInstance := mediator.GetMediatorInstance()
+	mediatorInstance.Publish(mediator.ChainHeadEvent{
+		Number:  block.Number,
+		Hash:    block.Hash,
+		Payload: string(blockJSON),
+	})
+
+	for _, address := range touchedAccounts(block) {
+		accountData, err := account.GetAccount(address)
+		if err != nil {
+			fmt.Printf("Failed to load changed account %s : %v\n", address, err)
+			continue
+		}
+		mediatorInstance.Publish(mediator.AccountChangedEvent{
+			Address:     address,
+			Balance:     accountData.Balance.String(),
+			Nonce:       accountData.Nonce,
+			BlockNumber: block.Number,
+		})
+	}
 }
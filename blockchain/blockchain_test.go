@@ -1,80 +1,26 @@
 package blockchain
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
+	"path/filepath"
+	"simple_p2p_client/account"
 	"simple_p2p_client/leveldb"
+	"simple_p2p_client/mediator"
 	"simple_p2p_client/utils"
+	"strings"
 	"testing"
-)
-
-func TestSyncFutureToPending(t *testing.T) {
-	// 초기 멤풀 상태 설정
-	mp := &Mempool{
-		pending: map[string]map[uint64]Transaction{
-			"account1": {
-				1: {Nonce: 1},
-				2: {Nonce: 2},
-				3: {Nonce: 3},
-			},
-		},
-		future: map[string]map[uint64]Transaction{
-			"account1": {
-				4: {Nonce: 4},
-				5: {Nonce: 5},
-				6: {Nonce: 6},
-				8: {Nonce: 8}, // 연속되지 않은 Nonce
-			},
-		},
-	}
-
-	// SyncFutureToPending 호출
-	mp.SyncFutureToPending()
+	"time"
 
-	// 결과 확인
-	expectedPending := map[uint64]Transaction{
-		1: {Nonce: 1},
-		2: {Nonce: 2},
-		3: {Nonce: 3},
-		4: {Nonce: 4},
-		5: {Nonce: 5},
-		6: {Nonce: 6},
-	}
-	expectedFuture := map[uint64]Transaction{
-		8: {Nonce: 8},
-	}
-
-	// 검증: Pending 확인
-	if len(mp.pending["account1"]) != len(expectedPending) {
-		t.Errorf("Pending size mismatch: expected %d, got %d", len(expectedPending), len(mp.pending["account1"]))
-	}
-	for nonce, tx := range expectedPending {
-		if _, exists := mp.pending["account1"][nonce]; !exists {
-			t.Errorf("Pending missing transaction with nonce %d", nonce)
-		}
-		if mp.pending["account1"][nonce] != tx {
-			t.Errorf("Pending transaction mismatch for nonce %d: expected %+v, got %+v", nonce, tx, mp.pending["account1"][nonce])
-		}
-	}
-
-	// 검증: Future 확인
-	if len(mp.future["account1"]) != len(expectedFuture) {
-		t.Errorf("Future size mismatch: expected %d, got %d", len(expectedFuture), len(mp.future["account1"]))
-	}
-	for nonce, tx := range expectedFuture {
-		if _, exists := mp.future["account1"][nonce]; !exists {
-			t.Errorf("Future missing transaction with nonce %d", nonce)
-		}
-		if mp.future["account1"][nonce] != tx {
-			t.Errorf("Future transaction mismatch for nonce %d: expected %+v, got %+v", nonce, tx, mp.future["account1"][nonce])
-		}
-	}
-
-	// 테스트 결과 출력 (선택 사항)
-	fmt.Println("Pending after Sync:", mp.pending["account1"])
-	fmt.Println("Future after Sync:", mp.future["account1"])
-}
+	"github.com/ethereum/go-ethereum/crypto"
+	db "github.com/syndtr/goleveldb/leveldb"
+)
 
 func TestExtractTransactionsForBlock(t *testing.T) {
 	// Initialize mempool
@@ -124,6 +70,40 @@ func TestExtractTransactionsForBlock(t *testing.T) {
 	}
 }
 
+func TestExtractTransactionsByFee(t *testing.T) {
+	mp := &Mempool{
+		pending: map[string]map[uint64]Transaction{
+			"A": {
+				1: {Nonce: 1, From: "A", Fee: big.NewInt(1)},
+				2: {Nonce: 2, From: "A", Fee: big.NewInt(100)},
+			},
+			"B": {
+				1: {Nonce: 1, From: "B", Fee: big.NewInt(10)},
+			},
+			"C": {
+				1: {Nonce: 1, From: "C"},
+			},
+		},
+	}
+
+	blockTxs := mp.ExtractTransactionsForBlock(10)
+
+	// B(10) -> A nonce 1(1) -> A nonce 2(100) -> C(0) : 높은 수수료라도 같은 주소의 앞 nonce보다 먼저 나올 수 없음
+	expected := []struct {
+		from  string
+		nonce uint64
+	}{{"B", 1}, {"A", 1}, {"A", 2}, {"C", 1}}
+
+	if len(blockTxs) != len(expected) {
+		t.Fatalf("Expected %d transactions, got %d", len(expected), len(blockTxs))
+	}
+	for i, want := range expected {
+		if blockTxs[i].From != want.from || blockTxs[i].Nonce != want.nonce {
+			t.Errorf("tx %d : expected %s/%d, got %s/%d", i, want.from, want.nonce, blockTxs[i].From, blockTxs[i].Nonce)
+		}
+	}
+}
+
 func TestBuildMerkleTree(t *testing.T) {
 	// 유틸리티 함수: Keccak256 해시 계산
 	hash := func(input string) string {
@@ -301,3 +281,838 @@ func TestCreateNewBlockTwo(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessTransactionErrorCodes(t *testing.T) {
+	// 서명자와 from이 다른 트랜잭션
+	privateKey, _ := crypto.GenerateKey()
+	from := "0xde589C867174C349d00e9b582867aF5c13A74679"
+	to := "0x7a227D5902cA52C0C3C61304533bfF4632Fce145"
+	messageHash := utils.Keccak256([]byte(fmt.Sprintf("%s%s%s%d", from, to, "100", 1)))
+	signature, _ := crypto.Sign(messageHash, privateKey)
+
+	tests := []struct {
+		name         string
+		message      string
+		expectedCode TxErrorCode
+	}{
+		{
+			name:         "Malformed JSON",
+			message:      "{not json",
+			expectedCode: TxErrInvalid,
+		},
+		{
+			name:         "Missing signature",
+			message:      fmt.Sprintf(`{"from":"%s","to":"%s","value":100,"nonce":1,"signature":""}`, from, to),
+			expectedCode: TxErrInvalid,
+		},
+		{
+			name:         "Signed by another key",
+			message:      fmt.Sprintf(`{"from":"%s","to":"%s","value":100,"nonce":1,"signature":"%s"}`, from, to, hex.EncodeToString(signature)),
+			expectedCode: TxErrBadSignature,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := ProcessTransaction(test.message)
+			txErr, ok := err.(*TxError)
+			if !ok {
+				t.Fatalf("expected *TxError, got %v", err)
+			}
+			if txErr.Code != test.expectedCode {
+				t.Errorf("expected code %d, got %d (%s)", test.expectedCode, txErr.Code, txErr.Message)
+			}
+		})
+	}
+}
+
+func TestMempoolInspection(t *testing.T) {
+	mp := &Mempool{
+		pending: map[string]map[uint64]Transaction{
+			"A": {1: {Hash: "0xa1", From: "A", Nonce: 1}, 2: {Hash: "0xa2", From: "A", Nonce: 2}},
+			"B": {1: {Hash: "0xb1", From: "B", Nonce: 1}},
+		},
+		future: map[string]map[uint64]Transaction{
+			"A": {5: {Hash: "0xa5", From: "A", Nonce: 5}},
+		},
+	}
+
+	pending, future := mp.Status()
+	if pending != 3 || future != 1 {
+		t.Errorf("expected status 3/1, got %d/%d", pending, future)
+	}
+
+	pendingA, futureA := mp.ContentFrom("a")
+	if len(pendingA) != 2 || len(futureA) != 1 {
+		t.Errorf("expected 2 pending, 1 future for A, got %d/%d", len(pendingA), len(futureA))
+	}
+
+	// 제거 후 빈 주소는 큐에서 삭제
+	if !mp.RemoveTransaction("0xB1") {
+		t.Errorf("expected 0xb1 to be removed")
+	}
+	if _, exists := mp.pending["B"]; exists {
+		t.Errorf("expected empty account B to be removed from pending")
+	}
+	if mp.RemoveTransaction("0xb1") {
+		t.Errorf("expected second remove to return false")
+	}
+
+	// pending 중간을 제거하면 뒤의 nonce는 future로 이동, 로컬 표시 해제
+	mp.locals = map[string]bool{"0xa1": true}
+	if !mp.RemoveTransaction("0xa1") {
+		t.Fatalf("expected 0xa1 to be removed")
+	}
+	if _, exists := mp.pending["A"]; exists {
+		t.Errorf("expected no pending transactions for A, got %v", mp.pending["A"])
+	}
+	if len(mp.future["A"]) != 2 || mp.future["A"][2].Hash != "0xa2" {
+		t.Errorf("expected nonce 2 to be moved to future, got %v", mp.future["A"])
+	}
+	if mp.locals["0xa1"] {
+		t.Errorf("expected removed transaction to be unmarked as local")
+	}
+	if _, exists := mp.addedAt["0xa2"]; !exists {
+		t.Errorf("expected demoted transaction to get a new added time for future expiry")
+	}
+
+	// future 한도를 넘는 트랜잭션은 옮기지 않고 제거
+	mp = &Mempool{
+		pending: map[string]map[uint64]Transaction{
+			"C": {1: {Hash: "0xc1", From: "C", Nonce: 1}, 2: {Hash: "0xc2", From: "C", Nonce: 2}, 3: {Hash: "0xc3", From: "C", Nonce: 3}},
+		},
+		future: map[string]map[uint64]Transaction{},
+		limits: MempoolLimits{AccountFutureSlots: 1},
+	}
+	mp.RemoveTransaction("0xc1")
+	if len(mp.future["C"]) != 1 || mp.future["C"][2].Hash != "0xc2" {
+		t.Errorf("expected only nonce 2 to be moved to future, got %v", mp.future["C"])
+	}
+}
+
+func TestCheckReplacement(t *testing.T) {
+	oldTx := Transaction{Hash: "0x01", Nonce: 1, Fee: big.NewInt(100)}
+
+	tests := []struct {
+		name        string
+		newTx       Transaction
+		expectedErr error
+	}{
+		{"Same transaction", Transaction{Hash: "0x01", Nonce: 1, Fee: big.NewInt(100)}, ErrDuplicateTransaction},
+		{"Same fee", Transaction{Hash: "0x02", Nonce: 1, Fee: big.NewInt(100)}, ErrReplaceUnderpriced},
+		{"Bump too small", Transaction{Hash: "0x02", Nonce: 1, Fee: big.NewInt(109)}, ErrReplaceUnderpriced},
+		{"Enough bump", Transaction{Hash: "0x02", Nonce: 1, Fee: big.NewInt(110)}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkReplacement(oldTx, test.newTx)
+			if !errors.Is(err, test.expectedErr) {
+				t.Errorf("expected %v, got %v", test.expectedErr, err)
+			}
+		})
+	}
+
+	// 기존 수수료가 0이면 0보다 크기만 하면 교체 가능
+	if err := checkReplacement(Transaction{Hash: "0x01", Nonce: 1}, Transaction{Hash: "0x02", Nonce: 1, Fee: big.NewInt(1)}); err != nil {
+		t.Errorf("expected replacement of zero-fee tx, got %v", err)
+	}
+}
+
+func TestSubmitPendingReplacement(t *testing.T) {
+	privateKey, _ := crypto.GenerateKey()
+	from, _ := account.PublicKeyToAddress(crypto.FromECDSAPub(&privateKey.PublicKey))
+	to := "0x7a227D5902cA52C0C3C61304533bfF4632Fce145"
+	if _, err := account.StoreAccountForGenesisMiner(from, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	InitMempool()
+
+	sub := mediator.GetMediatorInstance().Subscribe(10, mediator.PolicyDrop, mediator.TxValidationResultType)
+	defer sub.Unsubscribe()
+
+	submit := func(value, nonce, fee int64) (string, error) {
+		hash := TransactionSigningHash(from, to, big.NewInt(value), uint64(nonce), big.NewInt(fee))
+		signature, _ := account.SignMessage(hash, privateKey)
+		message, _ := json.Marshal(RawTransaction{From: from, To: to, Value: big.NewInt(value), Nonce: uint64(nonce), Fee: big.NewInt(fee), Signature: signature})
+		return SubmitTransaction(string(message))
+	}
+
+	// pending 1, 2 실행 후 잔액 100 - 51 - 41 = 8
+	if _, err := submit(50, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := submit(40, 2, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// 수수료를 4 올린 교체는 pending 잔액 안이므로 교체, 다시 전파
+	replacement, err := submit(50, 1, 5)
+	if err != nil {
+		t.Fatalf("expected replacement to be accepted, got %v", err)
+	}
+	if tx := defaultMempool.pending[from][1]; tx.Hash != replacement {
+		t.Errorf("expected pending nonce 1 to be %s, got %s", replacement, tx.Hash)
+	}
+	published := []string{}
+	for len(sub.Events()) > 0 {
+		published = append(published, (<-sub.Events()).(mediator.TxValidationResult).TxHash)
+	}
+	if len(published) != 3 || published[2] != replacement {
+		t.Errorf("expected replacement to be published for gossip, got %v", published)
+	}
+
+	// 수수료를 15 더 올리면 nonce 2를 실행할 잔액이 부족
+	var txErr *TxError
+	if _, err := submit(50, 1, 20); !errors.As(err, &txErr) || txErr.Code != TxErrInsufficientFunds {
+		t.Errorf("expected insufficient funds for overdrawing replacement, got %v", err)
+	}
+	if tx := defaultMempool.pending[from][1]; tx.Hash != replacement {
+		t.Errorf("expected rejected replacement to keep %s, got %s", replacement, tx.Hash)
+	}
+}
+
+func TestMempoolLimitsAndEviction(t *testing.T) {
+	mp := &Mempool{
+		pending: map[string]map[uint64]Transaction{
+			"A": {1: {Hash: "0xa1", From: "A", Nonce: 1, Fee: big.NewInt(5)}, 2: {Hash: "0xa2", From: "A", Nonce: 2, Fee: big.NewInt(1)}},
+			"B": {1: {Hash: "0xb1", From: "B", Nonce: 1, Fee: big.NewInt(3)}},
+		},
+		future: map[string]map[uint64]Transaction{},
+		limits: MempoolLimits{GlobalPendingSlots: 3, AccountPendingSlots: 2, AccountFutureSlots: 1},
+	}
+
+	// 주소 한도 초과
+	if err := mp.reservePending(Transaction{Hash: "0xa3", From: "A", Nonce: 3, Fee: big.NewInt(100)}); !errors.Is(err, ErrPoolFull) {
+		t.Errorf("expected account pending limit error, got %v", err)
+	}
+
+	// 전체 한도 초과 + 가장 낮은 수수료(0xa2)보다 낮으면 거절
+	if err := mp.reservePending(Transaction{Hash: "0xc1", From: "C", Nonce: 1, Fee: big.NewInt(1)}); !errors.Is(err, ErrPoolFull) {
+		t.Errorf("expected global pending limit error, got %v", err)
+	}
+
+	// 더 높은 수수료면 A의 마지막 nonce 트랜잭션을 밀어냄
+	if err := mp.reservePending(Transaction{Hash: "0xc1", From: "C", Nonce: 1, Fee: big.NewInt(2)}); err != nil {
+		t.Fatalf("expected eviction, got %v", err)
+	}
+	if _, exists := mp.pending["A"][2]; exists {
+		t.Errorf("expected A nonce 2 to be evicted")
+	}
+	if _, exists := mp.pending["A"][1]; !exists {
+		t.Errorf("expected A nonce 1 to remain")
+	}
+}
+
+func TestMempoolRevalidate(t *testing.T) {
+	now := time.Now()
+	mp := &Mempool{
+		pending: map[string]map[uint64]Transaction{
+			"A": {
+				3: {Hash: "0xa3", From: "A", Nonce: 3, Value: big.NewInt(10)}, // 이미 사용된 nonce
+				4: {Hash: "0xa4", From: "A", Nonce: 4, Value: big.NewInt(60)},
+				5: {Hash: "0xa5", From: "A", Nonce: 5, Value: big.NewInt(60)}, // 잔액 부족
+				6: {Hash: "0xa6", From: "A", Nonce: 6, Value: big.NewInt(1)},  // 앞 nonce가 빠져 future로
+			},
+		},
+		future: map[string]map[uint64]Transaction{
+			"B": {
+				2: {Hash: "0xb2", From: "B", Nonce: 2, Value: big.NewInt(1)}, // 이어지는 nonce라 pending으로
+				9: {Hash: "0xb9", From: "B", Nonce: 9, Value: big.NewInt(1)}, // 만료
+			},
+			"C": {1: {Hash: "0xc1", From: "C", Nonce: 1, Value: big.NewInt(1)}}, // 없는 계정
+		},
+		limits:  MempoolLimits{FutureLifetime: time.Hour},
+		addedAt: map[string]time.Time{"0xb9": now.Add(-2 * time.Hour), "0xb2": now},
+	}
+
+	accounts := map[string]*account.Account{
+		"A": {Balance: big.NewInt(100), Nonce: 3},
+		"B": {Balance: big.NewInt(100), Nonce: 1},
+	}
+	promoted := mp.revalidate(func(address string) (*account.Account, error) {
+		if acc, exists := accounts[address]; exists {
+			return acc, nil
+		}
+		return nil, account.ErrUnknownAccount
+	}, now)
+
+	if len(mp.pending["A"]) != 1 || mp.pending["A"][4].Hash != "0xa4" {
+		t.Errorf("expected only A nonce 4 in pending, got %v", mp.pending["A"])
+	}
+	if len(mp.future["A"]) != 1 || mp.future["A"][6].Hash != "0xa6" {
+		t.Errorf("expected A nonce 6 to be demoted to future, got %v", mp.future["A"])
+	}
+	if mp.pending["B"][2].Hash != "0xb2" {
+		t.Errorf("expected B nonce 2 to be promoted, got %v", mp.pending["B"])
+	}
+	// 알림 대상은 future에서 승격된 트랜잭션만 (원래 pending이던 A nonce 4는 제외)
+	if len(promoted) != 1 || promoted[0] != "0xb2" {
+		t.Errorf("expected only 0xb2 to be reported as promoted, got %v", promoted)
+	}
+	if _, exists := mp.future["B"]; exists {
+		t.Errorf("expected expired B nonce 9 to be dropped, got %v", mp.future["B"])
+	}
+	if _, exists := mp.future["C"]; exists {
+		t.Errorf("expected unknown account C to be dropped")
+	}
+	if _, exists := mp.addedAt["0xb9"]; exists {
+		t.Errorf("expected addedAt of dropped tx to be pruned")
+	}
+}
+
+func TestTxJournalRotateAndLoad(t *testing.T) {
+	j := &txJournal{path: filepath.Join(t.TempDir(), "transactions.journal")}
+
+	txs := []Transaction{
+		{Hash: "0x01", From: "A", To: "B", Value: big.NewInt(1), Nonce: 1, Signature: "sig1"},
+		{Hash: "0x02", From: "A", To: "B", Value: big.NewInt(2), Nonce: 2, Fee: big.NewInt(5), Signature: "sig2"},
+	}
+	if err := j.rotate(txs); err != nil {
+		t.Fatalf("rotate failed: %v", err)
+	}
+	if err := j.insert(`{"from":"C","to":"D","value":3,"nonce":1,"signature":"sig3"}`); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	j.writer.Close()
+
+	// 두 번째 트랜잭션은 검증 실패로 버려졌다고 가정
+	var replayed []RawTransaction
+	loaded, dropped, err := j.load(func(message string) error {
+		var rawTx RawTransaction
+		if err := json.Unmarshal([]byte(message), &rawTx); err != nil {
+			return err
+		}
+		if rawTx.Nonce == 2 {
+			return account.ErrNonceTooLow
+		}
+		replayed = append(replayed, rawTx)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if loaded != 2 || dropped != 1 {
+		t.Errorf("expected 2 loaded, 1 dropped, got %d/%d", loaded, dropped)
+	}
+	if len(replayed) != 2 || replayed[0].Signature != "sig1" || replayed[1].From != "C" {
+		t.Errorf("unexpected replayed transactions: %+v", replayed)
+	}
+}
+
+func TestPendingStateAndPromotion(t *testing.T) {
+	mp := &Mempool{
+		pending: map[string]map[uint64]Transaction{
+			"A": {
+				4: {Hash: "0xa4", From: "A", Nonce: 4, Value: big.NewInt(10), Fee: big.NewInt(1)},
+				5: {Hash: "0xa5", From: "A", Nonce: 5, Value: big.NewInt(10)},
+			},
+		},
+		future: map[string]map[uint64]Transaction{
+			"A": {
+				6: {Hash: "0xa6", From: "A", Nonce: 6, Value: big.NewInt(20)},
+				7: {Hash: "0xa7", From: "A", Nonce: 7, Value: big.NewInt(100)}, // 잔액 부족으로 승격 불가
+			},
+		},
+	}
+
+	// DB nonce 3, 잔액 50 : pending 4, 5를 적용하면 nonce 5, 잔액 29
+	state := mp.pendingStateLocked("A", &account.Account{Nonce: 3, Balance: big.NewInt(50)})
+	if state.Nonce != 5 || state.Balance.Int64() != 29 {
+		t.Fatalf("expected pending state 5/29, got %d/%s", state.Nonce, state.Balance)
+	}
+
+	promoted := mp.promoteFutureLocked("A", state)
+	if _, exists := mp.pending["A"][6]; !exists || len(promoted) != 1 || promoted[0] != "0xa6" {
+		t.Errorf("expected nonce 6 to be promoted, got %v", promoted)
+	}
+	if _, exists := mp.future["A"][7]; !exists {
+		t.Errorf("expected unaffordable nonce 7 to stay in future")
+	}
+
+	// nonce가 끊긴 pending은 추출하지 않음
+	mp.pending["B"] = map[uint64]Transaction{1: {From: "B", Nonce: 1}, 3: {From: "B", Nonce: 3}}
+	for _, tx := range mp.ExtractTransactionsForBlock(10) {
+		if tx.From == "B" && tx.Nonce == 3 {
+			t.Errorf("expected gapped nonce 3 of B not to be extracted")
+		}
+	}
+}
+
+func TestValidateBlockTransactionsOverlay(t *testing.T) {
+	privateKey, _ := crypto.GenerateKey()
+	from, _ := account.PublicKeyToAddress(crypto.FromECDSAPub(&privateKey.PublicKey))
+	to := "0x7a227D5902cA52C0C3C61304533bfF4632Fce145"
+	miner := "0xde589C867174C349d00e9b582867aF5c13A74679"
+
+	signedTx := func(value int64, nonce uint64) Transaction {
+		hash := TransactionSigningHash(from, to, big.NewInt(value), nonce, nil)
+		signature, _ := account.SignMessage(hash, privateKey)
+		tx, _, _ := CreateTransaction(from, to, signature, big.NewInt(value), nonce, nil)
+		return tx
+	}
+
+	newOverlay := func() *stateOverlay {
+		overlay := newStateOverlay()
+		overlay.load = func(address string) (*account.Account, error) {
+			if address == from {
+				return &account.Account{Balance: big.NewInt(100), Nonce: 0}, nil
+			}
+			return &account.Account{Balance: big.NewInt(0)}, nil
+		}
+		return overlay
+	}
+
+	tests := []struct {
+		name    string
+		txs     []Transaction
+		wantErr string
+	}{
+		{"Sequential", []Transaction{signedTx(40, 1), signedTx(60, 2)}, ""},
+		{"Overspend together", []Transaction{signedTx(60, 1), signedTx(60, 2)}, "insufficient funds"},
+		{"Duplicate", []Transaction{signedTx(10, 1), signedTx(10, 1)}, "duplicate transaction"},
+		{"Nonce gap", []Transaction{signedTx(10, 1), signedTx(10, 3)}, "nonce gap"},
+		{"Nonce reused", []Transaction{signedTx(10, 1), signedTx(20, 1)}, "nonce too low"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateBlockTransactions(&Block{Miner: miner, Transaction: test.txs}, newOverlay())
+			if test.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected valid block, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("expected error containing %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestApplyTransactionSelfTransfer(t *testing.T) {
+	chainConfig = DefaultGenesis()
+	privateKey, _ := crypto.GenerateKey()
+	from, _ := account.PublicKeyToAddress(crypto.FromECDSAPub(&privateKey.PublicKey))
+	miner := "0xde589C867174C349d00e9b582867aF5c13A74679"
+
+	hash := TransactionSigningHash(from, from, big.NewInt(30), 1, big.NewInt(2))
+	signature, _ := account.SignMessage(hash, privateKey)
+	tx, _, _ := CreateTransaction(from, from, signature, big.NewInt(30), 1, big.NewInt(2))
+
+	overlay := newStateOverlay()
+	overlay.load = func(address string) (*account.Account, error) {
+		if address == from {
+			return &account.Account{Balance: big.NewInt(100), Nonce: 0}, nil
+		}
+		return &account.Account{Balance: big.NewInt(0)}, nil
+	}
+	if err := overlay.applyTransaction(tx, miner); err != nil {
+		t.Fatalf("applyTransaction failed : %v", err)
+	}
+
+	// 자신에게 보내면 수수료만 빠지고 nonce는 증가
+	batch := new(db.Batch)
+	if err := overlay.putAccounts(batch); err != nil {
+		t.Fatal(err)
+	}
+	written := map[string]account.Account{}
+	batch.Replay(replayFunc(func(key, value []byte) {
+		var accountData account.Account
+		json.Unmarshal(value, &accountData)
+		written[strings.TrimPrefix(string(key), "account:")] = accountData
+	}))
+	if sender := written[from]; sender.Balance.Int64() != 98 || sender.Nonce != 1 {
+		t.Errorf("expected sender 98/nonce 1, got %s/nonce %d", sender.Balance, sender.Nonce)
+	}
+	if minerAccount := written[miner]; minerAccount.Balance.Int64() != 2 {
+		t.Errorf("expected miner fee 2, got %s", minerAccount.Balance)
+	}
+
+	// 같은 트랜잭션을 다시 실행할 수 없음
+	if err := overlay.applyTransaction(tx, miner); err == nil || !strings.Contains(err.Error(), "nonce too low") {
+		t.Errorf("expected replayed self transfer to fail, got %v", err)
+	}
+}
+
+func TestValidateBlockHeader(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	parent := &Block{Number: 10, Hash: "parent", Timestamp: uint64(now.Unix()) - 60}
+
+	tests := []struct {
+		name    string
+		block   Block
+		wantErr string
+	}{
+		{"Valid", Block{Number: 11, ParentHash: "parent", Timestamp: uint64(now.Unix())}, ""},
+		{"Number skipped", Block{Number: 12, ParentHash: "parent", Timestamp: uint64(now.Unix())}, "number"},
+		{"Wrong parent", Block{Number: 11, ParentHash: "other", Timestamp: uint64(now.Unix())}, "parent hash"},
+		{"Too close to parent", Block{Number: 11, ParentHash: "parent", Timestamp: parent.Timestamp + 1}, "at least"},
+		{"Too far in future", Block{Number: 11, ParentHash: "parent", Timestamp: uint64(now.Add(time.Hour).Unix())}, "future"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateBlockHeader(&test.block, parent, now)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected valid header, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("expected error containing %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRewardSchedule(t *testing.T) {
+	schedule := RewardSchedule{
+		InitialReward:   big.NewInt(1000),
+		HalvingInterval: 10,
+		TailEmission:    big.NewInt(100),
+		FeeBurnPercent:  30,
+	}
+
+	tests := []struct {
+		number uint64
+		reward int64
+	}{
+		{2, 1000}, {11, 1000}, {12, 500}, {22, 250}, {32, 125}, {42, 100}, {1000, 100},
+	}
+	for _, test := range tests {
+		if reward := schedule.BlockReward(test.number); reward.Int64() != test.reward {
+			t.Errorf("block %d : expected reward %d, got %s", test.number, test.reward, reward)
+		}
+	}
+
+	if burned := schedule.BurnedFee(big.NewInt(15)); burned.Int64() != 4 {
+		t.Errorf("expected 4 burned of 15, got %s", burned)
+	}
+
+	// 반감만 있고 tail이 없으면 결국 0
+	noTail := RewardSchedule{InitialReward: big.NewInt(1000), HalvingInterval: 1}
+	if reward := noTail.BlockReward(100); reward.Sign() != 0 {
+		t.Errorf("expected reward to reach 0, got %s", reward)
+	}
+}
+
+func TestChainFileBlockRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	blocks := []*Block{
+		{Number: 1, Hash: "a1", ParentHash: "0x0", Transaction: []Transaction{}},
+		{Number: 2, Hash: "b2", ParentHash: "a1", Transaction: []Transaction{{Hash: "0x01", From: "A", To: "B", Value: big.NewInt(5), Nonce: 1}}},
+	}
+	for _, block := range blocks {
+		if err := writeChainFileBlock(&buf, block); err != nil {
+			t.Fatalf("writeChainFileBlock failed: %v", err)
+		}
+	}
+	data := buf.Bytes()
+
+	reader := bytes.NewReader(data)
+	for _, expected := range blocks {
+		block, err := readChainFileBlock(reader)
+		if err != nil {
+			t.Fatalf("readChainFileBlock failed: %v", err)
+		}
+		if block.Number != expected.Number || block.Hash != expected.Hash || len(block.Transaction) != len(expected.Transaction) {
+			t.Fatalf("expected block %+v, got %+v", expected, block)
+		}
+	}
+	if _, err := readChainFileBlock(reader); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF at end of file, got %v", err)
+	}
+
+	// 잘린 파일은 EOF가 아닌 에러
+	truncated := bytes.NewReader(data[:len(data)-3])
+	if _, err := readChainFileBlock(truncated); err != nil {
+		t.Fatalf("expected first block to be readable, got %v", err)
+	}
+	if _, err := readChainFileBlock(truncated); err == nil || errors.Is(err, io.EOF) {
+		t.Fatalf("expected truncation error, got %v", err)
+	}
+
+	// gzip 파일은 자동으로 압축 해제, 압축하지 않은 파일은 그대로
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write(data)
+	gz.Close()
+	for _, input := range [][]byte{compressed.Bytes(), data} {
+		reader, err := chainFileReader(bytes.NewReader(input))
+		if err != nil {
+			t.Fatalf("chainFileReader failed: %v", err)
+		}
+		for _, expected := range blocks {
+			block, err := readChainFileBlock(reader)
+			if err != nil || block.Hash != expected.Hash {
+				t.Fatalf("expected block %s, got %+v, %v", expected.Hash, block, err)
+			}
+		}
+	}
+}
+
+func TestVerifyStoredBlock(t *testing.T) {
+	config := DefaultGenesis()
+	privateKey, _ := crypto.HexToECDSA("7ac125dda168b44ee9fc0d8db3a804ef86b3cc50206a0112b25373d622cf78f7") // 제네시스 miner 테스트 키
+	miner := config.Miner
+	to := "0x7a227D5902cA52C0C3C61304533bfF4632Fce145"
+
+	configHash, err := config.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesis := &Block{Number: 1, ParentHash: "0x0", MerkleRoot: "0x0", Transaction: []Transaction{}, Miner: miner, ConfigHash: configHash}
+	genesis.Hash = computeBlockHash(genesis)
+
+	hash := TransactionSigningHash(miner, to, big.NewInt(10), 1, big.NewInt(1))
+	signature, _ := account.SignMessage(hash, privateKey)
+	tx, _, _ := CreateTransaction(miner, to, signature, big.NewInt(10), 1, big.NewInt(1))
+	merkleRoot, _ := BuildMerkleTree([]string{strings.TrimPrefix(tx.Hash, "0x")})
+	block := &Block{Number: 2, ParentHash: genesis.Hash, Timestamp: 100, MerkleRoot: merkleRoot, Transaction: []Transaction{tx}, Miner: miner, Coinbase: config.Reward.newCoinbase(2, []Transaction{tx})}
+	block.Hash = computeBlockHash(block)
+
+	newScratch := func() *stateOverlay {
+		return &stateOverlay{accounts: make(map[string]*account.Account), load: func(string) (*account.Account, error) {
+			return &account.Account{Balance: big.NewInt(0)}, nil
+		}, reward: config.Reward}
+	}
+
+	scratch := newScratch()
+	result := &VerifyResult{Supply: big.NewInt(0)}
+	if err := verifyStoredBlock(config, genesis, nil, scratch, result); err != nil {
+		t.Fatalf("genesis verification failed : %v", err)
+	}
+	if err := verifyStoredBlock(config, block, genesis, scratch, result); err != nil {
+		t.Fatalf("block verification failed : %v", err)
+	}
+	minerAccount, _ := scratch.get(miner)
+	if minerAccount.Balance.Int64() != 10000-11+1+1000 || minerAccount.Nonce != 1 {
+		t.Errorf("unexpected miner state : %s, nonce %d", minerAccount.Balance, minerAccount.Nonce)
+	}
+	if result.Supply.Int64() != 11000 {
+		t.Errorf("expected supply 11000, got %s", result.Supply)
+	}
+
+	// 설정이 다른 체인의 제네시스 (miner는 같고 초기 발행량이 다름) : 해시가 다르고 설정 해시 검증에서 거절
+	otherConfig := DefaultGenesis()
+	otherConfig.Balance = big.NewInt(20000)
+	otherHash, _ := otherConfig.Hash()
+	other := &Block{Number: 1, ParentHash: "0x0", MerkleRoot: "0x0", Transaction: []Transaction{}, Miner: miner, ConfigHash: otherHash}
+	other.Hash = computeBlockHash(other)
+	if other.Hash == genesis.Hash {
+		t.Error("expected genesis blocks of different configs to have different hashes")
+	}
+	if err := verifyStoredBlock(config, other, nil, newScratch(), &VerifyResult{Supply: big.NewInt(0)}); err == nil || !strings.Contains(err.Error(), "genesis config hash") {
+		t.Errorf("expected genesis config hash mismatch, got %v", err)
+	}
+
+	// 트랜잭션 값이 바뀐 블록 (해시는 다시 계산해도 서명, 트랜잭션 해시가 맞지 않음)
+	tampered := *block
+	tampered.Transaction = []Transaction{tx}
+	tampered.Transaction[0].Value = big.NewInt(20)
+	scratch = newScratch()
+	verifyStoredBlock(config, genesis, nil, scratch, &VerifyResult{Supply: big.NewInt(0)})
+	if err := verifyStoredBlock(config, &tampered, genesis, scratch, &VerifyResult{Supply: big.NewInt(0)}); err == nil {
+		t.Error("expected tampered transaction to fail verification")
+	}
+}
+
+func TestSnapshotVerifyAndFile(t *testing.T) {
+	genesisConfig := DefaultGenesis()
+	genesis := &Block{Number: 1, ParentHash: "0x0", MerkleRoot: "0x0", Transaction: []Transaction{}, Miner: genesisConfig.Miner}
+	genesis.Hash = computeBlockHash(genesis)
+	head := &Block{Number: 2, ParentHash: genesis.Hash, Timestamp: 100, MerkleRoot: "0x0", Transaction: []Transaction{}, Miner: genesisConfig.Miner}
+	head.Hash = computeBlockHash(head)
+
+	accounts := []SnapshotAccount{
+		{Address: "0x7a227d5902ca52c0c3c61304533bff4632fce145", Balance: big.NewInt(10), Nonce: 0},
+		{Address: "0xde589c867174c349d00e9b582867af5c13a74679", Balance: big.NewInt(10990), Nonce: 1},
+	}
+	stateRoot, err := computeStateRoot(accounts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := &Snapshot{Number: 2, BlockHash: head.Hash, StateRoot: stateRoot, Supply: big.NewInt(11000),
+		Genesis: genesisConfig, GenesisBlock: genesis, Head: head, Accounts: accounts}
+	if err := snapshot.Verify(); err != nil {
+		t.Fatalf("snapshot verification failed : %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), SnapshotFileName(snapshot))
+	if err := WriteSnapshotFile(path, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadSnapshotFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Verify(); err != nil {
+		t.Fatalf("loaded snapshot verification failed : %v", err)
+	}
+
+	// 잔액을 바꾸면 상태 머클루트가 맞지 않음
+	loaded.Accounts[0].Balance = big.NewInt(11)
+	if err := loaded.Verify(); err == nil {
+		t.Error("expected modified account to fail verification")
+	}
+}
+
+func TestPruneStateHistory(t *testing.T) {
+	dbInstance, err := db.OpenFile(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbInstance.Close()
+
+	// A는 1, 3, 6번, B는 1, 6번 블록에서 바뀜
+	changes := map[uint64][]string{1: {"A", "B"}, 3: {"A"}, 6: {"A", "B"}}
+	for number, addresses := range changes {
+		for _, address := range addresses {
+			dbInstance.Put(historyKey(address, number), []byte("{}"), nil)
+		}
+		addressesJSON, _ := json.Marshal(addresses)
+		dbInstance.Put(changesKey(number), addressesJSON, nil)
+	}
+
+	checkKept := func(expected map[string]bool) {
+		t.Helper()
+		for key, kept := range expected {
+			if _, err := dbInstance.Get([]byte(key), nil); (err == nil) != kept {
+				t.Errorf("key %s : expected kept=%v, got err %v", key, kept, err)
+			}
+		}
+	}
+
+	// 5번 블록부터 조회 : A는 3번 기록, B는 1번 기록이 필요
+	if err := pruneStateHistory(dbInstance, 5); err != nil {
+		t.Fatalf("pruneStateHistory failed: %v", err)
+	}
+	checkKept(map[string]bool{
+		string(historyKey("A", 1)): false,
+		string(historyKey("A", 3)): true,
+		string(historyKey("A", 6)): true,
+		string(historyKey("B", 1)): true,
+		string(historyKey("B", 6)): true,
+		string(changesKey(1)):      false,
+		string(changesKey(3)):      false,
+		string(changesKey(6)):      true,
+	})
+
+	// 7번 블록부터 조회 : 6번 기록만 필요 (남겨 두었던 B의 1번 기록도 삭제)
+	if err := pruneStateHistory(dbInstance, 7); err != nil {
+		t.Fatalf("pruneStateHistory failed: %v", err)
+	}
+	checkKept(map[string]bool{
+		string(historyKey("A", 3)): false,
+		string(historyKey("A", 6)): true,
+		string(historyKey("B", 1)): false,
+		string(historyKey("B", 6)): true,
+		string(changesKey(6)):      false,
+	})
+}
+
+func TestPutAddressIndexes(t *testing.T) {
+	block := &Block{Number: 12, Transaction: []Transaction{
+		{Hash: "0x01", From: "0xAbC", To: "0xdef"},
+		{Hash: "0x02", From: "0xdef", To: "0xDEF"},
+	}}
+	batch := new(db.Batch)
+	putAddressIndexes(batch, block)
+
+	// 자신에게 보낸 트랜잭션은 한 번만 기록, 주소는 소문자
+	keys := []string{}
+	batch.Replay(replayFunc(func(key, value []byte) { keys = append(keys, string(key)) }))
+	expected := []string{
+		"addrtx:0xabc:00000000000000000012:000000",
+		"addrtx:0xdef:00000000000000000012:000000",
+		"addrtx:0xdef:00000000000000000012:000001",
+	}
+	if strings.Join(keys, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected keys %v, got %v", expected, keys)
+	}
+
+	if number, index, err := parseAddressTxCursor("12:1"); err != nil || number != 12 || index != 1 {
+		t.Errorf("unexpected cursor parse result : %d, %d, %v", number, index, err)
+	}
+	if _, _, err := parseAddressTxCursor("12"); err == nil {
+		t.Error("expected invalid cursor error")
+	}
+}
+
+func TestAddressTransactionsPaging(t *testing.T) {
+	dbInstance, err := db.OpenFile(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbInstance.Close()
+
+	// A가 보내거나 받은 트랜잭션 : 1-0, 2-1, 4-0(자신에게), 4-1, 5-0
+	tx := func(hash, from, to string) Transaction { return Transaction{Hash: hash, From: from, To: to} }
+	blocks := map[uint64]*Block{
+		1: {Number: 1, Transaction: []Transaction{tx("0x10", "0xAbC", "0xb")}},
+		2: {Number: 2, Transaction: []Transaction{tx("0x20", "0xc", "0xd"), tx("0x21", "0xb", "0xabc")}},
+		3: {Number: 3, Transaction: []Transaction{}},
+		4: {Number: 4, Transaction: []Transaction{tx("0x40", "0xabc", "0xABC"), tx("0x41", "0xabc", "0xc"), tx("0x42", "0xc", "0xb")}},
+		5: {Number: 5, Transaction: []Transaction{tx("0x50", "0xd", "0xabc")}},
+	}
+	batch := new(db.Batch)
+	for _, block := range blocks {
+		putAddressIndexes(batch, block)
+	}
+	if err := dbInstance.Write(batch, nil); err != nil {
+		t.Fatal(err)
+	}
+	loadBlock := func(number uint64) (*Block, error) { return blocks[number], nil }
+
+	hashes := func(txs []AddressTx) string {
+		result := []string{}
+		for _, addressTx := range txs {
+			result = append(result, addressTx.Transaction.Hash)
+		}
+		return strings.Join(result, ",")
+	}
+
+	// 2개씩 페이지를 넘기면 겹치거나 빠지는 트랜잭션 없이 최신 순
+	pages := []string{}
+	cursor := ""
+	for i := 0; i < 10; i++ {
+		txs, next, err := addressTransactions(dbInstance, "0xABC", 0, 0, 2, cursor, loadBlock)
+		if err != nil {
+			t.Fatalf("page %d : %v", i, err)
+		}
+		pages = append(pages, hashes(txs))
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if got := strings.Join(pages, "|"); got != "0x50,0x41|0x40,0x21|0x10" {
+		t.Errorf("unexpected pages : %s", got)
+	}
+
+	// 블록 범위와 방향
+	txs, next, err := addressTransactions(dbInstance, "0xabc", 2, 4, 0, "", loadBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashes(txs) != "0x41,0x40,0x21" || next != "" {
+		t.Fatalf("unexpected range result : %s, cursor %q", hashes(txs), next)
+	}
+	for i, direction := range []string{"out", "self", "in"} {
+		if txs[i].Direction != direction {
+			t.Errorf("tx %s : expected direction %s, got %s", txs[i].Transaction.Hash, direction, txs[i].Direction)
+		}
+	}
+
+	if _, _, err := addressTransactions(dbInstance, "0xabc", 0, 0, MaxAddressTxLimit+1, "", loadBlock); err == nil {
+		t.Error("expected limit error")
+	}
+}
+
+type replayFunc func(key, value []byte)
+
+func (f replayFunc) Put(key, value []byte) { f(key, value) }
+func (f replayFunc) Delete(key []byte)     {}
@@ -0,0 +1,203 @@
+package blockchain
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	db "github.com/syndtr/goleveldb/leveldb"
+)
+
+// 체인 파일 : 블록마다 4바이트 길이(빅엔디언) + 블록 JSON을 번호 순으로 기록, gzip으로 압축할 수 있음
+// 같은 체인, 같은 범위면 항상 같은 파일이 만들어짐 (gzip 헤더에 시각, 파일 이름을 기록하지 않음)
+const maxChainFileBlockSize = 32 << 20
+
+// 체인 파일 내보내기, 가져오기 진행 상황
+type ChainFileProgress struct {
+	Number    uint64 // 마지막으로 처리한 블록 번호
+	Processed int    // 내보내거나 새로 실행해 저장한 블록 수
+	Skipped   int    // 가져오기 : 이미 로컬 체인에 있어 건너뛴 블록 수 (이어서 가져오기)
+}
+
+// 블록 하나를 처리할 때마다 호출, 에러를 반환하면 중단
+type ChainFileProgressFunc func(ChainFileProgress) error
+
+// first부터 last까지의 블록을 파일로 내보내기 (last가 0이면 마지막 블록까지), 내보낸 블록 수 반환
+func ExportChain(w io.Writer, first, last uint64, compress bool, progress ChainFileProgressFunc) (int, error) {
+	latest, err := GetLatestBlock()
+	if err != nil {
+		return 0, err
+	}
+	if first == 0 {
+		first = 1
+	}
+	if last == 0 {
+		last = latest.Number
+	}
+	if first > last || last > latest.Number {
+		return 0, fmt.Errorf("invalid range %d-%d, chain head is %d", first, last, latest.Number)
+	}
+
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+	writer := bufio.NewWriter(w)
+
+	status := ChainFileProgress{}
+	for number := first; number <= last; number++ {
+		block, err := GetBlockByNumber(number)
+		if err != nil {
+			return status.Processed, fmt.Errorf("failed to read block %d : %v", number, err)
+		}
+		if IsBodyPruned(block) {
+			return status.Processed, fmt.Errorf("transactions of block %d have been pruned", number)
+		}
+		if err := writeChainFileBlock(writer, block); err != nil {
+			return status.Processed, err
+		}
+		status.Number = number
+		status.Processed++
+		if progress != nil {
+			if err := progress(status); err != nil {
+				return status.Processed, err
+			}
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return status.Processed, err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return status.Processed, err
+		}
+	}
+	return status.Processed, nil
+}
+
+// 파일의 블록을 순서대로 검증, 실행해 가져오기 (gzip이면 자동으로 압축 해제)
+// 로컬 체인에 이미 있는 블록은 해시가 같은지만 확인하고 건너뛰므로, 중단된 가져오기를 같은 파일로 이어서 할 수 있음
+func ImportChain(r io.Reader, progress ChainFileProgressFunc) (ChainFileProgress, error) {
+	status := ChainFileProgress{}
+
+	reader, err := chainFileReader(r)
+	if err != nil {
+		return status, err
+	}
+
+	for {
+		block, err := readChainFileBlock(reader)
+		if errors.Is(err, io.EOF) {
+			return status, nil
+		}
+		if err != nil {
+			return status, err
+		}
+
+		head, err := GetLatestBlock()
+		if err != nil {
+			return status, err
+		}
+
+		switch {
+		case block.Number <= head.Number:
+			// 이미 있는 블록 : 로컬 체인과 같은 블록이어야 함 (제네시스 포함)
+			local, err := GetBlockByNumber(block.Number)
+			if errors.Is(err, db.ErrNotFound) {
+				// 스냅샷으로 복원한 체인 : 스냅샷 이전 블록은 DB에 없음
+				status.Skipped++
+				break
+			}
+			if err != nil {
+				return status, err
+			}
+			if local.Hash != block.Hash {
+				return status, fmt.Errorf("block %d mismatch : local %s, file %s", block.Number, local.Hash, block.Hash)
+			}
+			status.Skipped++
+		case block.Number == head.Number+1:
+			if err := ImportBlock(block); err != nil {
+				return status, fmt.Errorf("block %d (%s) : %v", block.Number, block.Hash, err)
+			}
+			status.Processed++
+		default:
+			return status, fmt.Errorf("missing blocks before %d, local chain head is %d", block.Number, head.Number)
+		}
+
+		status.Number = block.Number
+		if progress != nil {
+			if err := progress(status); err != nil {
+				return status, err
+			}
+		}
+	}
+}
+
+// gzip 파일이면 압축 해제 reader 반환
+// 압축하지 않은 파일의 첫 바이트는 블록 길이의 최상위 바이트라 gzip 매직 넘버(0x1f 0x8b)가 될 수 없음
+func chainFileReader(r io.Reader) (*bufio.Reader, error) {
+	reader := bufio.NewReader(r)
+	magic, err := reader.Peek(2)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return reader, nil
+		}
+		return nil, err
+	}
+	if magic[0] != 0x1f || magic[1] != 0x8b {
+		return reader, nil
+	}
+
+	gz, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip chain file : %v", err)
+	}
+	return bufio.NewReader(gz), nil
+}
+
+func writeChainFileBlock(w io.Writer, block *Block) error {
+	blockJSON, err := json.Marshal(block)
+	if err != nil {
+		return fmt.Errorf("failed to marshal block %d : %v", block.Number, err)
+	}
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(blockJSON)))
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(blockJSON)
+	return err
+}
+
+// 블록 하나 읽기, 파일 끝이면 io.EOF
+func readChainFileBlock(r io.Reader) (*Block, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("truncated chain file")
+		}
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(size[:])
+	if length > maxChainFileBlockSize {
+		return nil, fmt.Errorf("block entry too large : %d bytes", length)
+	}
+	blockJSON := make([]byte, length)
+	if _, err := io.ReadFull(r, blockJSON); err != nil {
+		return nil, fmt.Errorf("truncated chain file : %v", err)
+	}
+
+	var block Block
+	if err := json.Unmarshal(blockJSON, &block); err != nil {
+		return nil, fmt.Errorf("failed to parse block : %v", err)
+	}
+	return &block, nil
+}
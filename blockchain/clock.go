@@ -0,0 +1,19 @@
+package blockchain
+
+import "time"
+
+// 현재 시각을 알려주는 시계 (블록 타임스탬프 규칙을 테스트에서 고정된 시각으로 검증하기 위해 사용)
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+var clock Clock = systemClock{}
+
+// 블록 생성, 검증에 사용할 시계 교체
+func SetClock(c Clock) {
+	clock = c
+}
@@ -0,0 +1,255 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"simple_p2p_client/account"
+	"simple_p2p_client/leveldb"
+	"simple_p2p_client/utils"
+
+	db "github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// 블록 보상 정책
+type RewardSchedule struct {
+	InitialReward   *big.Int `json:"initialReward"`          // 첫 블록 보상
+	HalvingInterval uint64   `json:"halvingInterval"`        // 보상이 절반이 되는 블록 수, 0이면 반감 없음
+	TailEmission    *big.Int `json:"tailEmission,omitempty"` // 반감으로 이보다 작아지면 이 값을 계속 지급
+	FeeBurnPercent  uint64   `json:"feeBurnPercent"`         // 수수료 중 소각하는 비율(%), 나머지는 miner에게
+}
+
+// 제네시스 설정 (모든 노드가 같은 설정을 사용해야 함)
+type GenesisConfig struct {
+	Miner   string         `json:"miner"`   // 제네시스 블록 miner, 초기 잔액을 받음
+	Balance *big.Int       `json:"balance"` // 초기 발행량
+	Reward  RewardSchedule `json:"reward"`
+}
+
+// 블록에 기록되는 보상 내역
+type Coinbase struct {
+	Reward *big.Int `json:"reward"` // 블록 보상
+	Fees   *big.Int `json:"fees"`   // miner가 받은 수수료
+	Burned *big.Int `json:"burned"` // 소각된 수수료
+}
+
+const (
+	genesisConfigKey = "genesis:config"
+	totalSupplyKey   = "supply"
+)
+
+var (
+	genesisFile string
+	chainConfig = DefaultGenesis()
+)
+
+// 기존 하드코딩 값과 같은 기본 설정 : 초기 10000, 블록당 1000, 반감, 소각 없음
+func DefaultGenesis() GenesisConfig {
+	return GenesisConfig{
+		Miner:   "0xde589C867174C349d00e9b582867aF5c13A74679",
+		Balance: big.NewInt(10000),
+		Reward: RewardSchedule{
+			InitialReward: big.NewInt(1000),
+		},
+	}
+}
+
+// 제네시스 설정 파일 경로 지정 (체인을 처음 만들 때만 사용, 이후에는 DB에 저장된 설정 사용)
+func SetGenesisFile(path string) {
+	genesisFile = path
+}
+
+// 현재 체인의 제네시스 설정
+func ChainConfig() GenesisConfig {
+	return chainConfig
+}
+
+// JSON 제네시스 설정 파일 읽기
+func LoadGenesisFile(path string) (GenesisConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return GenesisConfig{}, fmt.Errorf("failed to read genesis file : %v", err)
+	}
+
+	config := DefaultGenesis()
+	if err := json.Unmarshal(data, &config); err != nil {
+		return GenesisConfig{}, fmt.Errorf("failed to parse genesis file : %v", err)
+	}
+	if err := config.validate(); err != nil {
+		return GenesisConfig{}, err
+	}
+	return config, nil
+}
+
+// 제네시스 설정 해시 : 설정 JSON의 Keccak256 (제네시스 블록 해시에 포함)
+func (c GenesisConfig) Hash() (string, error) {
+	configJSON, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize genesis config : %v", err)
+	}
+	return utils.BytesToHex(utils.Keccak256(configJSON)), nil
+}
+
+// 제네시스 블록에 기록된 설정 해시와 설정 비교 (설정 해시가 없는 예전 제네시스는 miner만 비교)
+func (c GenesisConfig) checkGenesisBlock(block *Block) error {
+	if block.Miner != c.Miner {
+		return fmt.Errorf("genesis miner is %s, genesis config has %s", block.Miner, c.Miner)
+	}
+	if block.ConfigHash == "" {
+		return nil
+	}
+	configHash, err := c.Hash()
+	if err != nil {
+		return err
+	}
+	if block.ConfigHash != configHash {
+		return fmt.Errorf("genesis config hash is %s, genesis config has %s", block.ConfigHash, configHash)
+	}
+	return nil
+}
+
+func (c GenesisConfig) validate() error {
+	if !account.IsValidAddress(c.Miner) {
+		return fmt.Errorf("invalid genesis miner address : %s", c.Miner)
+	}
+	if c.Balance == nil || c.Balance.Sign() < 0 {
+		return fmt.Errorf("invalid genesis balance : must be a non-negative integer")
+	}
+	if c.Reward.InitialReward == nil || c.Reward.InitialReward.Sign() < 0 {
+		return fmt.Errorf("invalid initial reward : must be a non-negative integer")
+	}
+	if c.Reward.TailEmission != nil && c.Reward.TailEmission.Sign() < 0 {
+		return fmt.Errorf("invalid tail emission : must be a non-negative integer")
+	}
+	if c.Reward.FeeBurnPercent > 100 {
+		return fmt.Errorf("invalid fee burn percent : %d", c.Reward.FeeBurnPercent)
+	}
+	return nil
+}
+
+// 블록 번호의 보상 (제네시스 다음 블록(2번)부터 지급)
+func (r RewardSchedule) BlockReward(number uint64) *big.Int {
+	reward := new(big.Int).Set(r.InitialReward)
+	if number > 2 && r.HalvingInterval > 0 {
+		halvings := (number - 2) / r.HalvingInterval
+		if halvings >= uint64(reward.BitLen()) {
+			reward.SetInt64(0)
+		} else {
+			reward.Rsh(reward, uint(halvings))
+		}
+	}
+	if r.TailEmission != nil && reward.Cmp(r.TailEmission) < 0 {
+		reward.Set(r.TailEmission)
+	}
+	return reward
+}
+
+// 수수료 중 소각되는 몫
+func (r RewardSchedule) BurnedFee(fee *big.Int) *big.Int {
+	burned := new(big.Int).Mul(fee, big.NewInt(int64(r.FeeBurnPercent)))
+	return burned.Div(burned, big.NewInt(100))
+}
+
+// 블록 번호와 트랜잭션으로 보상 내역 계산
+func (r RewardSchedule) newCoinbase(number uint64, transactions []Transaction) *Coinbase {
+	coinbase := &Coinbase{
+		Reward: r.BlockReward(number),
+		Fees:   big.NewInt(0),
+		Burned: big.NewInt(0),
+	}
+	for _, tx := range transactions {
+		burned := r.BurnedFee(tx.FeeOrZero())
+		coinbase.Burned.Add(coinbase.Burned, burned)
+		coinbase.Fees.Add(coinbase.Fees, new(big.Int).Sub(tx.FeeOrZero(), burned))
+	}
+	return coinbase
+}
+
+// 받은 블록의 보상 내역이 규칙대로인지 확인
+func (r RewardSchedule) validateCoinbase(block *Block) error {
+	if block.Coinbase == nil {
+		return fmt.Errorf("missing coinbase")
+	}
+	expected := r.newCoinbase(block.Number, block.Transaction)
+	if !bigEqual(block.Coinbase.Reward, expected.Reward) || !bigEqual(block.Coinbase.Fees, expected.Fees) || !bigEqual(block.Coinbase.Burned, expected.Burned) {
+		return fmt.Errorf("invalid coinbase : expected reward %s, fees %s, burned %s", expected.Reward, expected.Fees, expected.Burned)
+	}
+	return nil
+}
+
+func bigEqual(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
+
+// 처음 실행 시 사용할 제네시스 설정 : 파일이 지정되어 있으면 파일, 아니면 기본값
+func resolveGenesisConfig() (GenesisConfig, error) {
+	if genesisFile == "" {
+		return DefaultGenesis(), nil
+	}
+	return LoadGenesisFile(genesisFile)
+}
+
+// DB에 저장된 제네시스 설정 불러오기 (설정 저장 이전에 만든 체인이면 기본값)
+func loadStoredGenesisConfig(dbInstance *db.DB) (GenesisConfig, error) {
+	data, err := dbInstance.Get([]byte(genesisConfigKey), nil)
+	if errors.Is(err, db.ErrNotFound) {
+		return DefaultGenesis(), nil
+	}
+	if err != nil {
+		return GenesisConfig{}, err
+	}
+
+	var config GenesisConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return GenesisConfig{}, fmt.Errorf("failed to parse stored genesis config : %v", err)
+	}
+	return config, nil
+}
+
+// 총 발행량 조회, 기록이 없는 체인이면 모든 계정 잔액을 합산해 기록
+func GetTotalSupply() (*big.Int, error) {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dbinstance : %v", err)
+	}
+
+	data, err := dbInstance.Get([]byte(totalSupplyKey), nil)
+	if err == nil {
+		supply, ok := new(big.Int).SetString(string(data), 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid total supply value : %s", data)
+		}
+		return supply, nil
+	}
+	if !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+
+	supply := big.NewInt(0)
+	iter := dbInstance.NewIterator(util.BytesPrefix([]byte("account:")), nil)
+	for iter.Next() {
+		var accountData account.Account
+		if err := json.Unmarshal(iter.Value(), &accountData); err != nil {
+			iter.Release()
+			return nil, fmt.Errorf("failed to parse account %s : %v", iter.Key(), err)
+		}
+		if accountData.Balance != nil {
+			supply.Add(supply, accountData.Balance)
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	if err := dbInstance.Put([]byte(totalSupplyKey), []byte(supply.String()), nil); err != nil {
+		return nil, err
+	}
+	return supply, nil
+}
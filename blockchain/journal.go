@@ -0,0 +1,220 @@
+package blockchain
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"simple_p2p_client/constants"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 로컬(RPC)로 제출된 트랜잭션 저널 : 한 줄에 RawTransaction JSON 하나
+// 노드 재시작 시 다시 검증해 멤풀에 넣고, 주기적으로 멤풀에 남은 트랜잭션만으로 다시 씀
+type txJournal struct {
+	path   string
+	writer *os.File
+	mu     sync.Mutex
+}
+
+var journal *txJournal
+
+// 저널 교체 주기
+var journalRotation = constants.MempoolJournalRotation
+
+// 저널 파일 경로 설정 (노드 데이터 디렉토리 안), 비어 있으면 저널을 사용하지 않음
+func SetJournalPath(path string) {
+	journal = &txJournal{path: path}
+}
+
+// 저널 교체 주기 설정 (StartMempoolJournal 전에 호출)
+func SetJournalRotation(interval time.Duration) {
+	journalRotation = interval
+}
+
+// 저널 복구 후 새로 쓰기 시작, 주기적으로 저널 교체
+// InitMempool 이후, 트랜잭션을 받기 전에 호출
+func StartMempoolJournal() error {
+	if journal == nil || journal.path == "" {
+		return nil
+	}
+
+	loaded, dropped, err := journal.load(func(message string) error {
+		txHash, _, err := ProcessTransaction(message)
+		if err != nil {
+			return err
+		}
+		defaultMempool.markLocal(txHash)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load transaction journal : %v", err)
+	}
+	fmt.Printf("[Mempool] Loaded %d transactions from journal, %d dropped\n", loaded, dropped)
+
+	if err := journal.rotate(defaultMempool.localTransactions()); err != nil {
+		return fmt.Errorf("failed to rotate transaction journal : %v", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(journalRotation)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := journal.rotate(defaultMempool.localTransactions()); err != nil {
+				fmt.Printf("[Mempool] Failed to rotate journal : %v\n", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// 로컬 트랜잭션을 저널에 추가
+func journalTransaction(txHash, rawTransactionMessage string) {
+	if defaultMempool == nil {
+		return
+	}
+	defaultMempool.markLocal(txHash)
+
+	if journal == nil {
+		return
+	}
+	if err := journal.insert(rawTransactionMessage); err != nil {
+		fmt.Printf("[Mempool] Failed to journal transaction %s : %v\n", txHash, err)
+	}
+}
+
+// 저널의 트랜잭션을 하나씩 add로 전달, (성공, 실패) 수 반환
+func (j *txJournal) load(add func(message string) error) (int, int, error) {
+	file, err := os.Open(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	loaded, dropped := 0, 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		if err := add(line); err != nil {
+			// 이미 블록에 담겼거나 잔액이 바뀐 트랜잭션은 버림
+			fmt.Printf("[Mempool] Dropping journaled transaction : %v\n", err)
+			dropped++
+			continue
+		}
+		loaded++
+	}
+	return loaded, dropped, scanner.Err()
+}
+
+func (j *txJournal) insert(message string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.writer == nil {
+		return errors.New("journal is not open")
+	}
+	_, err := j.writer.WriteString(message + "\n")
+	return err
+}
+
+// 현재 멤풀의 로컬 트랜잭션으로 저널을 새로 쓰고 이어쓰기용으로 다시 엶
+func (j *txJournal) rotate(txs []Transaction) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.writer != nil {
+		j.writer.Close()
+		j.writer = nil
+	}
+
+	tmpPath := j.path + ".new"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		message, err := json.Marshal(toRawTransaction(tx))
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		if _, err := tmp.Write(append(message, '\n')); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		return err
+	}
+
+	j.writer, err = os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[Mempool] Journal rotated, %d transactions\n", len(txs))
+	return nil
+}
+
+// 멤풀 트랜잭션을 제출 형식으로 변환
+func toRawTransaction(tx Transaction) RawTransaction {
+	return RawTransaction{
+		From:      tx.From,
+		To:        tx.To,
+		Value:     tx.Value,
+		Nonce:     tx.Nonce,
+		Fee:       tx.Fee,
+		Signature: tx.Signature,
+	}
+}
+
+// 로컬 트랜잭션 표시
+func (mp *Mempool) markLocal(txHash string) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	if mp.locals == nil {
+		mp.locals = make(map[string]bool)
+	}
+	mp.locals[txHash] = true
+}
+
+// 멤풀에 남아 있는 로컬 트랜잭션 (주소, nonce 순), 빠진 트랜잭션은 표시 해제
+func (mp *Mempool) localTransactions() []Transaction {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	var txs []Transaction
+	remaining := make(map[string]bool)
+	for _, queue := range []map[string]map[uint64]Transaction{mp.pending, mp.future} {
+		for _, accountTxs := range queue {
+			for _, tx := range accountTxs {
+				if mp.locals[tx.Hash] {
+					txs = append(txs, tx)
+					remaining[tx.Hash] = true
+				}
+			}
+		}
+	}
+	mp.locals = remaining
+
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].From != txs[j].From {
+			return txs[i].From < txs[j].From
+		}
+		return txs[i].Nonce < txs[j].Nonce
+	})
+	return txs
+}
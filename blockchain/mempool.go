@@ -2,35 +2,70 @@ package blockchain
 
 import (
 	"fmt"
+	"math/big"
 	"simple_p2p_client/account"
+	"simple_p2p_client/constants"
+	"simple_p2p_client/mediator"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 type Mempool struct {
 	pending map[string]map[uint64]Transaction
 	future  map[string]map[uint64]Transaction
+	limits  MempoolLimits        // 0인 항목은 제한 없음
+	addedAt map[string]time.Time // 트랜잭션 해시 => 멤풀에 들어온 시각 (future 만료 판단)
+	locals  map[string]bool      // RPC로 제출된 트랜잭션 해시 (저널에 기록)
 	mu      sync.Mutex
 }
 
 var defaultMempool *Mempool
 
+// 같은 nonce의 트랜잭션을 교체할 때 필요한 최소 수수료 인상률(%)
+var priceBump uint64 = constants.DefaultPriceBump
+
+// 멤풀 슬롯 제한 (InitMempool에서 적용)
+var mempoolLimits = DefaultMempoolLimits()
+
+// 교체 수수료 인상률 설정 (노드 시작 시 플래그로 지정)
+func SetPriceBump(percent uint64) {
+	priceBump = percent
+}
+
+// 멤풀 슬롯 제한 설정 (InitMempool 전에 호출)
+func SetMempoolLimits(limits MempoolLimits) {
+	mempoolLimits = limits
+}
+
 func InitMempool() {
 	defaultMempool = &Mempool{
 		pending: make(map[string]map[uint64]Transaction),
 		future:  make(map[string]map[uint64]Transaction),
+		limits:  mempoolLimits,
+		addedAt: make(map[string]time.Time),
+		locals:  make(map[string]bool),
 	}
 	fmt.Println("[Mempool] Initialized...")
 }
 
 // 멤풀에 트랜잭션 추가 (Nonce에 따라 pending, future로 나눠서)
+// pending에 들어간 트랜잭션(future에서 승격된 트랜잭션 포함)은 락을 푼 뒤 이벤트로 발행
 func (mp *Mempool) AddTransaction(tx Transaction, currentNonce uint64) error {
+	pendingHashes, err := mp.addTransaction(tx, currentNonce)
+	publishPendingTxs(pendingHashes)
+	return err
+}
+
+// pending에 들어간 트랜잭션 해시 반환
+func (mp *Mempool) addTransaction(tx Transaction, currentNonce uint64) ([]string, error) {
 
 	// DB에서 account 정보 가져옴
 
 	fromAccount, err := account.GetAccount(tx.From)
 	if err != nil {
-		return fmt.Errorf("failed to retrieve account from DB : %v", err)
+		return nil, fmt.Errorf("failed to retrieve account from DB : %v", err)
 	}
 
 	// DB에서 currentNonce를 가져옴
@@ -54,231 +89,196 @@ func (mp *Mempool) AddTransaction(tx Transaction, currentNonce uint64) error {
 		mp.future[tx.From] = make(map[uint64]Transaction)
 	}
 
-	// 중복 확인: pending과 future에 이미 존재하는 논스인지 확인
-	if _, exists := mp.pending[tx.From][tx.Nonce]; exists {
-		return fmt.Errorf("duplicate transaction: nonce %v already exists in pending", tx.Nonce)
+	// 중복 확인: pending과 future에 이미 존재하는 논스면 수수료를 올린 경우에만 교체
+	if oldTx, exists := mp.pending[tx.From][tx.Nonce]; exists {
+		if err := checkReplacement(oldTx, tx); err != nil {
+			return nil, fmt.Errorf("%w (pending)", err)
+		}
+		// 올린 비용만큼 pending 잔액이 줄어도 뒤의 pending 트랜잭션을 모두 실행할 수 있어야 함
+		state := mp.pendingStateLocked(tx.From, fromAccount)
+		extraCost := new(big.Int).Sub(tx.Cost(), oldTx.Cost())
+		if state.Balance.Cmp(extraCost) < 0 {
+			return nil, fmt.Errorf("%w : pending balance is %s, replacement costs %s more", account.ErrInsufficientFunds, state.Balance, extraCost)
+		}
+		mp.pending[tx.From][tx.Nonce] = tx
+		mp.markAdded(tx)
+		fmt.Printf("[Mempool] : pending tx %s is replaced by %s, nonce is : %v\n", oldTx.Hash, tx.Hash, tx.Nonce)
+		return []string{tx.Hash}, nil
 	}
-	if _, exists := mp.future[tx.From][tx.Nonce]; exists {
-		return fmt.Errorf("duplicate transaction: nonce %v already exists in future", tx.Nonce)
+	if oldTx, exists := mp.future[tx.From][tx.Nonce]; exists {
+		if err := checkReplacement(oldTx, tx); err != nil {
+			return nil, fmt.Errorf("%w (future)", err)
+		}
+		mp.future[tx.From][tx.Nonce] = tx
+		mp.markAdded(tx)
+		fmt.Printf("[Mempool] : future tx %s is replaced by %s, nonce is : %v\n", oldTx.Hash, tx.Hash, tx.Nonce)
+		return nil, nil
 	}
 
-	// pending에서 from의 마지막 논스 계산
-	highestPendingNonce := dbNonce
-	for nonce := range mp.pending[tx.From] {
-		if nonce > highestPendingNonce {
-			highestPendingNonce = nonce
-		}
+	// db 논스 이하는 이미 사용된 nonce
+	if tx.Nonce <= dbNonce {
+		return nil, fmt.Errorf("invalid transaction: %w : account nonce is %d, got %d", account.ErrNonceTooLow, dbNonce, tx.Nonce)
 	}
 
-	// highestPendingNonce + 1이면 Pending, 아니면 future에 저장
-	if tx.Nonce == highestPendingNonce+1 {
+	// pending을 모두 적용한 상태의 다음 nonce면 Pending, 그보다 크면 future에 저장
+	state := mp.pendingStateLocked(tx.From, fromAccount)
+	if tx.Nonce == state.Nonce+1 {
+		// pending 트랜잭션을 모두 실행한 뒤의 잔액으로 감당할 수 있어야 함
+		if state.Balance.Cmp(tx.Cost()) < 0 {
+			return nil, fmt.Errorf("%w : pending balance is %s, value + fee is %s", account.ErrInsufficientFunds, state.Balance, tx.Cost())
+		}
+
+		// 주소별, 전체 pending 한도 확인 (전체가 가득 차면 수수료가 더 낮은 트랜잭션을 밀어냄)
+		if err := mp.reservePending(tx); err != nil {
+			return nil, err
+		}
+
 		// Pending queue에 저장
 		mp.pending[tx.From][tx.Nonce] = tx
+		mp.markAdded(tx)
 		fmt.Printf("[Mempool] : tx is stored in pending, nonce is : %v\n", tx.Nonce)
 		fmt.Println()
 
-	} else if tx.Nonce > highestPendingNonce {
-		// Future queue에 저장
+		// 이어지는 future 트랜잭션 승격
+		state.Nonce++
+		state.Balance.Sub(state.Balance, tx.Cost())
+		return append([]string{tx.Hash}, mp.promoteFutureLocked(tx.From, state)...), nil
+
+	} else if tx.Nonce > state.Nonce+1 {
+		// 주소별, 전체 future 한도 확인
+		if err := mp.reserveFuture(tx); err != nil {
+			return nil, err
+		}
+
+		// Future queue에 저장 (밀어낸 트랜잭션이 같은 주소의 마지막 future였으면 맵이 지워졌을 수 있음)
+		if _, exists := mp.future[tx.From]; !exists {
+			mp.future[tx.From] = make(map[uint64]Transaction)
+		}
 		mp.future[tx.From][tx.Nonce] = tx
+		mp.markAdded(tx)
 		fmt.Printf("[Mempool] : tx is stored in future, nonce is : %v\n", tx.Nonce)
 		fmt.Println()
+		return nil, nil
 
 	} else {
-		// db 논스보다 작음
-		return fmt.Errorf("invalid transaction: nonce too low")
+		// pending 사이의 빈 nonce (pending은 항상 이어져 있으므로 발생하지 않음)
+		return nil, fmt.Errorf("invalid transaction: %w : pending nonce is %d, got %d", account.ErrNonceTooLow, state.Nonce, tx.Nonce)
 	}
-
-	return nil
 }
 
-// GetPendingTransactions 함수 : 특정 Account의 Pending 트랜잭션 가져오기
-func (mp *Mempool) GetPendingTransactions(account string) []Transaction {
-
-	mp.mu.Lock()
-
-	defer func() {
-
-		mp.mu.Unlock()
-	}()
-	txs := []Transaction{}
-	if pendingTxs, exists := mp.pending[account]; exists {
-		for _, tx := range pendingTxs {
-			txs = append(txs, tx)
+// pending 상태의 다음 nonce부터 이어지는 future 트랜잭션을 pending으로 이동 (잔액, 주소 한도 안에서), 락을 잡은 상태에서 호출
+// 승격한 트랜잭션 해시 반환
+func (mp *Mempool) promoteFutureLocked(address string, state PendingAccountState) []string {
+	var promoted []string
+	futureTxs := mp.future[address]
+	for {
+		tx, exists := futureTxs[state.Nonce+1]
+		if !exists || state.Balance.Cmp(tx.Cost()) < 0 {
+			break
 		}
-	}
-	return txs
-}
-
-// PromoteFutureToPending 함수 : Future 트랜잭션을 Pending으로 이동
-func (mp *Mempool) PromoteFutureToPending(account string, currentNonce uint64) {
-
-	mp.mu.Lock()
-
-	defer func() {
-
-		mp.mu.Unlock()
-	}()
-	if futureTxs, exists := mp.future[account]; exists {
-		for nonce, tx := range futureTxs {
-			if nonce == currentNonce+1 {
-				delete(mp.future[account], nonce)
-				mp.pending[account][nonce] = tx
-			}
+		if mp.limits.AccountPendingSlots > 0 && len(mp.pending[address]) >= mp.limits.AccountPendingSlots {
+			break
 		}
-	}
-}
-
-// SelectTransactionsForBlock : 블록 생성시 트랜잭션 선택
-func (mp *Mempool) SelectTransactionsForBlock(account string, currentNonce uint64) ([]Transaction, error) {
-
-	mp.mu.Lock()
-
-	defer func() {
 
-		mp.mu.Unlock()
-	}()
-	blockTxs := []Transaction{}
-
-	// Pending queue에서 트랜잭션 선택
-	if pendingTxs, exists := mp.pending[account]; exists {
-		for nonce := currentNonce + 1; ; nonce++ {
-			tx, exists := pendingTxs[nonce]
-			if !exists {
-				break
-			}
-			blockTxs = append(blockTxs, tx)
-			delete(mp.pending[account], nonce)
+		if _, exists := mp.pending[address]; !exists {
+			mp.pending[address] = make(map[uint64]Transaction)
 		}
+		mp.pending[address][tx.Nonce] = tx
+		delete(futureTxs, tx.Nonce)
+		state.Nonce++
+		state.Balance.Sub(state.Balance, tx.Cost())
+		promoted = append(promoted, tx.Hash)
+		fmt.Printf("[Mempool] : future tx is promoted to pending, nonce is : %v\n", tx.Nonce)
+	}
+	if len(futureTxs) == 0 {
+		delete(mp.future, address)
 	}
-	return blockTxs, nil
+	return promoted
 }
 
-// 멤풀 정리 :  블록 생성 시도시 유효한 Future를 Pending으로 옮기고 필요없는 트랜잭션 정리
-func (mp *Mempool) CleanMempool() {
-
-	mp.mu.Lock()
-
-	defer func() {
-
-		mp.mu.Unlock()
-	}()
-	for account, futureTxs := range mp.future {
-		if pendingTxs, exists := mp.pending[account]; exists {
-			// 현재 pending 논스 계산
-			currentNonce := uint64(0)
-			for nonce := range pendingTxs {
-				if nonce > currentNonce {
-					currentNonce = nonce
-				}
-			}
-
-			// Future에서 Pending으로 옮기기
-			for nonce, tx := range futureTxs {
-				if nonce == currentNonce+1 {
-					mp.pending[account][nonce] = tx
-					delete(mp.future[account], nonce)
-					currentNonce++
-				}
-			}
-
-			// Future 비우기
-			delete(mp.future, account)
-		}
+// pending에 들어간 트랜잭션 알림 (newPendingTransactions 구독)
+func publishPendingTxs(hashes []string) {
+	if len(hashes) == 0 {
+		return
 	}
+	mediator.GetMediatorInstance().Publish(mediator.NewPendingTxsEvent{Hashes: hashes})
 }
 
-// 블록 생성 시도시, 유효한 future 트랜잭션들을 Pending으로 옮기는
-func (mp *Mempool) SyncFutureToPending() {
-
-	mp.mu.Lock()
-
-	defer func() {
-
-		mp.mu.Unlock()
-	}()
-
-	// 멤풀 내 모든 계정 순회
-	for account, futureTxs := range mp.future {
-		pendingTxs, pendingExists := mp.pending[account]
-		if !pendingExists {
-			// pending에는 없다면 초기화
-			pendingTxs = make(map[uint64]Transaction)
-			mp.pending[account] = pendingTxs
-		}
-
-		// Pending에서 가장 높은 nonce 찾기
-		higestPendingNonce := uint64(0)
-		for nonce := range mp.pending[account] {
-			if nonce > higestPendingNonce {
-				higestPendingNonce = nonce
-			}
-		}
+// 같은 from, nonce의 트랜잭션 교체 가능 여부 : 수수료가 기존보다 priceBump% 이상 높아야 함
+func checkReplacement(oldTx, newTx Transaction) error {
+	if oldTx.Hash == newTx.Hash {
+		return fmt.Errorf("%w: nonce %v already exists", ErrDuplicateTransaction, newTx.Nonce)
+	}
 
-		// Future 트랜잭션을 pending으로 이동
-		for nonce := higestPendingNonce + 1; ; nonce++ {
-			tx, exists := futureTxs[nonce]
-			if !exists {
-				break // 연속된 Nonce 없으면 종료
-			}
+	oldFee := oldTx.FeeOrZero()
+	newFee := newTx.FeeOrZero()
 
-			// Pending으로 이동
-			pendingTxs[nonce] = tx
-			delete(futureTxs, nonce)
-		}
+	// 최소 수수료 = 기존 수수료 * (100 + priceBump) / 100, 기존 수수료가 0이면 0보다 크기만 하면 됨
+	minFee := new(big.Int).Mul(oldFee, big.NewInt(int64(100+priceBump)))
+	minFee.Div(minFee, big.NewInt(100))
 
-		// Future에서 해당 계정이 비어있으면 삭제
-		if len(futureTxs) == 0 {
-			delete(mp.future, account)
-		}
+	if newFee.Cmp(oldFee) <= 0 || newFee.Cmp(minFee) < 0 {
+		return fmt.Errorf("%w: nonce %v, fee %s must be at least %d%% higher than %s", ErrReplaceUnderpriced, newTx.Nonce, newFee, priceBump, oldFee)
 	}
+	return nil
 }
 
-// 라운드로빈(주소별)으로 트랜잭션 추출
+// 수수료 우선으로 트랜잭션 추출 : 주소별 가장 낮은 nonce의 트랜잭션 중 수수료가 가장 높은 것부터 선택 (주소 내 nonce 순서 유지)
 func (mp *Mempool) ExtractTransactionsForBlock(maxTxs int) []Transaction {
 
 	var blockTxs []Transaction
 
-	accounts := make([]string, 0, len(mp.pending))
-	for account := range mp.pending {
-		accounts = append(accounts, account)
-	}
+	// 주소별 pending nonce 오름차순 정렬
+	sortedNonces := make(map[string][]uint64, len(mp.pending))
+	for account, pendingTxs := range mp.pending {
+		nonces := make([]uint64, 0, len(pendingTxs))
+		for nonce := range pendingTxs {
+			nonces = append(nonces, nonce)
+		}
+		sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
 
-	for len(blockTxs) < maxTxs {
-		noMoreTxs := true
-		for _, account := range accounts {
-			if len(blockTxs) >= maxTxs {
+		// nonce가 끊기면 그 뒤는 실행할 수 없으므로 제외
+		for i := 1; i < len(nonces); i++ {
+			if nonces[i] != nonces[i-1]+1 {
+				nonces = nonces[:i]
 				break
 			}
+		}
+		sortedNonces[account] = nonces
+	}
 
-			pendingTxs := mp.pending[account]
-			if len(pendingTxs) == 0 {
-				fmt.Printf("No transactions for account: %s\n", account)
+	for len(blockTxs) < maxTxs {
+		// 각 주소의 다음 트랜잭션 중 수수료가 가장 높은 주소 선택 (같으면 주소 순)
+		bestAccount := ""
+		var bestTx Transaction
+		for account, nonces := range sortedNonces {
+			if len(nonces) == 0 {
 				continue
 			}
-
-			noMoreTxs = false
-			nonces := make([]uint64, 0, len(pendingTxs))
-			for nonce := range pendingTxs {
-				nonces = append(nonces, nonce)
+			tx := mp.pending[account][nonces[0]]
+			if bestAccount == "" {
+				bestAccount, bestTx = account, tx
+				continue
 			}
-			sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
-
-			lowestNonce := nonces[0]
-			fmt.Printf("[Mempool] Extracting transaction with nonce %d for account %s\n", lowestNonce, account)
-
-			blockTxs = append(blockTxs, pendingTxs[lowestNonce])
-			delete(pendingTxs, lowestNonce)
-
-			if len(pendingTxs) == 0 {
-				// fmt.Printf("All transactions for account %s processed. Removing from pending.\n", account)
-				delete(mp.pending, account)
+			cmp := tx.FeeOrZero().Cmp(bestTx.FeeOrZero())
+			if cmp > 0 || (cmp == 0 && account < bestAccount) {
+				bestAccount, bestTx = account, tx
 			}
 		}
 
-		if noMoreTxs {
+		if bestAccount == "" {
 			fmt.Println("No more transactions available for block.")
 			break
 		}
+
+		fmt.Printf("[Mempool] Extracting transaction with nonce %d, fee %s for account %s\n", bestTx.Nonce, bestTx.FeeOrZero(), bestAccount)
+		blockTxs = append(blockTxs, bestTx)
+		delete(mp.pending[bestAccount], bestTx.Nonce)
+		sortedNonces[bestAccount] = sortedNonces[bestAccount][1:]
+
+		if len(mp.pending[bestAccount]) == 0 {
+			delete(mp.pending, bestAccount)
+		}
 	}
 
 	fmt.Printf("Final block transactions: %v\n", blockTxs)
@@ -315,3 +315,162 @@ func (mp *Mempool) CleanMempoolAfterReceiveBlock(blockTxs []Transaction) {
 		}
 	}
 }
+
+// 해시로 멤풀(pending, future)의 트랜잭션 조회
+func (mp *Mempool) GetTransaction(hash string) (Transaction, bool) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	for _, queue := range []map[string]map[uint64]Transaction{mp.pending, mp.future} {
+		for _, accountTxs := range queue {
+			for _, tx := range accountTxs {
+				if strings.EqualFold(tx.Hash, hash) {
+					return tx, true
+				}
+			}
+		}
+	}
+	return Transaction{}, false
+}
+
+// 기본 멤풀에서 해시로 트랜잭션 조회
+func GetPoolTransaction(hash string) (Transaction, bool) {
+	if defaultMempool == nil {
+		return Transaction{}, false
+	}
+	return defaultMempool.GetTransaction(hash)
+}
+
+// 기본 멤풀 반환 (RPC 조회용)
+func GetMempool() *Mempool {
+	return defaultMempool
+}
+
+// pending, future 트랜잭션 수
+func (mp *Mempool) Status() (int, int) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	return countTransactions(mp.pending), countTransactions(mp.future)
+}
+
+// pending, future 전체 복사본 (주소 => nonce => 트랜잭션)
+func (mp *Mempool) Content() (map[string]map[uint64]Transaction, map[string]map[uint64]Transaction) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	return copyQueue(mp.pending, ""), copyQueue(mp.future, "")
+}
+
+// 특정 주소의 pending, future 복사본 (nonce => 트랜잭션)
+func (mp *Mempool) ContentFrom(address string) (map[uint64]Transaction, map[uint64]Transaction) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	pending := copyQueue(mp.pending, address)
+	future := copyQueue(mp.future, address)
+
+	pendingTxs, futureTxs := map[uint64]Transaction{}, map[uint64]Transaction{}
+	for _, txs := range pending {
+		for nonce, tx := range txs {
+			pendingTxs[nonce] = tx
+		}
+	}
+	for _, txs := range future {
+		for nonce, tx := range txs {
+			futureTxs[nonce] = tx
+		}
+	}
+	return pendingTxs, futureTxs
+}
+
+// 해시로 트랜잭션 제거, 제거했으면 true
+// 로컬 트랜잭션이면 재시작 후 다시 들어오지 않도록 저널을 다시 씀
+func (mp *Mempool) RemoveTransaction(hash string) bool {
+	removed, local := mp.removeTransaction(hash)
+	if local && mp == defaultMempool && journal != nil && journal.path != "" {
+		if err := journal.rotate(mp.localTransactions()); err != nil {
+			fmt.Printf("[Mempool] Failed to rotate journal : %v\n", err)
+		}
+	}
+	return removed
+}
+
+// pending에서 제거하면 같은 주소의 더 높은 nonce pending 트랜잭션은 nonce가 비므로 future로 옮김
+// (future 한도를 적용하고 만료 시간은 옮긴 시각부터, 자리가 없으면 제거)
+// (제거했는지, 로컬 트랜잭션을 제거했는지) 반환
+func (mp *Mempool) removeTransaction(hash string) (bool, bool) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	for _, queue := range []map[string]map[uint64]Transaction{mp.pending, mp.future} {
+		for _, accountTxs := range queue {
+			for _, tx := range accountTxs {
+				if !strings.EqualFold(tx.Hash, hash) {
+					continue
+				}
+				mp.dropTransaction(queue, tx)
+				local := mp.locals[tx.Hash]
+				delete(mp.locals, tx.Hash)
+
+				// 낮은 nonce부터 future로 옮김 (실행 가능성이 높은 트랜잭션이 자리를 먼저 차지)
+				var later []Transaction
+				for nonce, laterTx := range mp.pending[tx.From] {
+					if nonce > tx.Nonce {
+						later = append(later, laterTx)
+					}
+				}
+				sort.Slice(later, func(i, j int) bool { return later[i].Nonce < later[j].Nonce })
+
+				demoted := 0
+				for _, laterTx := range later {
+					mp.dropTransaction(mp.pending, laterTx)
+					if err := mp.reserveFuture(laterTx); err != nil {
+						fmt.Printf("[Mempool] Dropping tx %s : %v\n", laterTx.Hash, err)
+						if mp.locals[laterTx.Hash] {
+							delete(mp.locals, laterTx.Hash)
+							local = true
+						}
+						continue
+					}
+					if _, exists := mp.future[tx.From]; !exists {
+						mp.future[tx.From] = make(map[uint64]Transaction)
+					}
+					mp.future[tx.From][laterTx.Nonce] = laterTx
+					mp.markAdded(laterTx)
+					demoted++
+				}
+
+				fmt.Printf("[Mempool] Removed transaction, Hash : %s, %d later transactions moved to future\n", tx.Hash, demoted)
+				return true, local
+			}
+		}
+	}
+	return false, false
+}
+
+func countTransactions(queue map[string]map[uint64]Transaction) int {
+	count := 0
+	for _, accountTxs := range queue {
+		count += len(accountTxs)
+	}
+	return count
+}
+
+// 큐 복사, address가 비어있지 않으면 해당 주소만 (대소문자 무시)
+func copyQueue(queue map[string]map[uint64]Transaction, address string) map[string]map[uint64]Transaction {
+	copied := make(map[string]map[uint64]Transaction)
+	for from, accountTxs := range queue {
+		if address != "" && !strings.EqualFold(from, address) {
+			continue
+		}
+		if len(accountTxs) == 0 {
+			continue
+		}
+		copied[from] = make(map[uint64]Transaction, len(accountTxs))
+		for nonce, tx := range accountTxs {
+			copied[from][nonce] = tx
+		}
+	}
+	return copied
+}
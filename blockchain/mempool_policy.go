@@ -0,0 +1,274 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"simple_p2p_client/account"
+	"simple_p2p_client/constants"
+	"sort"
+	"time"
+)
+
+// 멤풀 용량 제한 (0인 항목은 제한 없음)
+type MempoolLimits struct {
+	GlobalPendingSlots  int           // 전체 pending 최대 트랜잭션 수
+	GlobalFutureSlots   int           // 전체 future 최대 트랜잭션 수
+	AccountPendingSlots int           // 주소 하나의 pending 최대 트랜잭션 수
+	AccountFutureSlots  int           // 주소 하나의 future 최대 트랜잭션 수
+	FutureLifetime      time.Duration // future 트랜잭션 만료 시간
+}
+
+func DefaultMempoolLimits() MempoolLimits {
+	return MempoolLimits{
+		GlobalPendingSlots:  constants.MempoolGlobalPendingSlots,
+		GlobalFutureSlots:   constants.MempoolGlobalFutureSlots,
+		AccountPendingSlots: constants.MempoolAccountPendingSlots,
+		AccountFutureSlots:  constants.MempoolAccountFutureSlots,
+		FutureLifetime:      constants.MempoolFutureLifetime,
+	}
+}
+
+// 트랜잭션이 멤풀에 들어온 시각 기록
+func (mp *Mempool) markAdded(tx Transaction) {
+	if mp.addedAt == nil {
+		mp.addedAt = make(map[string]time.Time)
+	}
+	mp.addedAt[tx.Hash] = time.Now()
+}
+
+// pending에 넣을 자리 확보 : 주소 한도 초과면 거절, 전체 한도 초과면 다른 주소의 가장 낮은 수수료 트랜잭션을 밀어냄
+// 락을 잡은 상태에서 호출
+func (mp *Mempool) reservePending(tx Transaction) error {
+	if mp.limits.AccountPendingSlots > 0 && len(mp.pending[tx.From]) >= mp.limits.AccountPendingSlots {
+		return fmt.Errorf("%w: account %s has %d pending transactions", ErrPoolFull, tx.From, len(mp.pending[tx.From]))
+	}
+	if mp.limits.GlobalPendingSlots <= 0 || countTransactions(mp.pending) < mp.limits.GlobalPendingSlots {
+		return nil
+	}
+
+	// 주소 내 nonce 연속성을 지키기 위해 각 주소의 마지막 nonce 트랜잭션만 밀어낼 후보로 삼음
+	var victim *Transaction
+	for from, accountTxs := range mp.pending {
+		if from == tx.From || len(accountTxs) == 0 {
+			continue
+		}
+		last := highestNonceTx(accountTxs)
+		if victim == nil || lowerPriority(last, *victim) {
+			victim = &last
+		}
+	}
+
+	if victim == nil || tx.FeeOrZero().Cmp(victim.FeeOrZero()) <= 0 {
+		return fmt.Errorf("%w: pending limit %d reached", ErrPoolFull, mp.limits.GlobalPendingSlots)
+	}
+
+	mp.dropTransaction(mp.pending, *victim)
+	fmt.Printf("[Mempool] Evicted pending tx %s (fee %s) for %s (fee %s)\n", victim.Hash, victim.FeeOrZero(), tx.Hash, tx.FeeOrZero())
+	return nil
+}
+
+// future에 넣을 자리 확보 : 만료된 트랜잭션을 먼저 정리하고, 그래도 가득 차면 가장 낮은 수수료 트랜잭션을 밀어냄
+// 락을 잡은 상태에서 호출
+func (mp *Mempool) reserveFuture(tx Transaction) error {
+	if mp.limits.AccountFutureSlots > 0 && len(mp.future[tx.From]) >= mp.limits.AccountFutureSlots {
+		return fmt.Errorf("%w: account %s has %d future transactions", ErrPoolFull, tx.From, len(mp.future[tx.From]))
+	}
+	if mp.limits.GlobalFutureSlots <= 0 || countTransactions(mp.future) < mp.limits.GlobalFutureSlots {
+		return nil
+	}
+
+	mp.expireFuture(time.Now())
+	if countTransactions(mp.future) < mp.limits.GlobalFutureSlots {
+		return nil
+	}
+
+	var victim *Transaction
+	for _, accountTxs := range mp.future {
+		for _, futureTx := range accountTxs {
+			futureTx := futureTx
+			if victim == nil || lowerPriority(futureTx, *victim) {
+				victim = &futureTx
+			}
+		}
+	}
+
+	if victim == nil || tx.FeeOrZero().Cmp(victim.FeeOrZero()) <= 0 {
+		return fmt.Errorf("%w: future limit %d reached", ErrPoolFull, mp.limits.GlobalFutureSlots)
+	}
+
+	mp.dropTransaction(mp.future, *victim)
+	fmt.Printf("[Mempool] Evicted future tx %s (fee %s) for %s (fee %s)\n", victim.Hash, victim.FeeOrZero(), tx.Hash, tx.FeeOrZero())
+	return nil
+}
+
+// 블록 실행 후 멤풀 재검증 (DB의 현재 nonce, 잔액 기준)
+//   - 이미 사용된 nonce, 잔액으로 감당할 수 없는 트랜잭션 제거
+//   - nonce가 이어지는 트랜잭션은 pending, 중간이 빈 트랜잭션은 future로 재배치
+//   - 만료된 future 트랜잭션 제거
+func (mp *Mempool) Revalidate() {
+	publishPendingTxs(mp.revalidate(account.GetAccount, time.Now()))
+}
+
+// future에서 pending으로 옮긴 트랜잭션 해시 반환
+func (mp *Mempool) revalidate(getAccount func(address string) (*account.Account, error), now time.Time) []string {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	var promoted []string
+
+	senders := make(map[string]bool)
+	for from := range mp.pending {
+		senders[from] = true
+	}
+	for from := range mp.future {
+		senders[from] = true
+	}
+
+	dropped := 0
+	for from := range senders {
+		// 주소의 pending, future를 합쳐 nonce 순으로 정렬
+		var txs []Transaction
+		for _, tx := range mp.pending[from] {
+			txs = append(txs, tx)
+		}
+		for _, tx := range mp.future[from] {
+			txs = append(txs, tx)
+		}
+		sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce < txs[j].Nonce })
+		previousFuture := mp.future[from]
+		delete(mp.pending, from)
+		delete(mp.future, from)
+
+		fromAccount, err := getAccount(from)
+		if err != nil {
+			fmt.Printf("[Mempool] Dropping %d transactions of %s : %v\n", len(txs), from, err)
+			dropped += len(txs)
+			continue
+		}
+
+		pending := make(map[uint64]Transaction)
+		future := make(map[uint64]Transaction)
+		balance := new(big.Int).Set(fromAccount.Balance)
+		nextNonce := fromAccount.Nonce + 1
+
+		gapped := false // 앞 nonce가 비었으면 이후 트랜잭션은 모두 future
+		for _, tx := range txs {
+			if tx.Nonce <= fromAccount.Nonce {
+				// 이미 블록에 담긴 nonce
+				dropped++
+				continue
+			}
+
+			hasSlot := mp.limits.AccountPendingSlots <= 0 || len(pending) < mp.limits.AccountPendingSlots
+			if gapped || tx.Nonce != nextNonce || !hasSlot {
+				gapped = true
+				future[tx.Nonce] = tx
+				continue
+			}
+
+			if balance.Cmp(tx.Cost()) < 0 {
+				// 잔액 부족 : 제거하면 뒤의 트랜잭션은 nonce가 비므로 future로 감
+				fmt.Printf("[Mempool] Dropping tx %s : insufficient funds\n", tx.Hash)
+				dropped++
+				gapped = true
+				continue
+			}
+			balance.Sub(balance, tx.Cost())
+			pending[tx.Nonce] = tx
+			nextNonce++
+			if _, wasFuture := previousFuture[tx.Nonce]; wasFuture {
+				promoted = append(promoted, tx.Hash)
+			}
+		}
+
+		if len(pending) > 0 {
+			mp.pending[from] = pending
+		}
+		if len(future) > 0 {
+			mp.future[from] = future
+		}
+	}
+
+	dropped += mp.expireFuture(now)
+	mp.pruneAddedAt()
+
+	if dropped > 0 {
+		fmt.Printf("[Mempool] Revalidated, %d transactions dropped\n", dropped)
+	}
+	return promoted
+}
+
+// FutureLifetime보다 오래 머문 future 트랜잭션 제거, 제거한 수 반환
+func (mp *Mempool) expireFuture(now time.Time) int {
+	if mp.limits.FutureLifetime <= 0 {
+		return 0
+	}
+
+	expired := 0
+	for from, accountTxs := range mp.future {
+		for nonce, tx := range accountTxs {
+			addedAt, exists := mp.addedAt[tx.Hash]
+			if !exists || now.Sub(addedAt) < mp.limits.FutureLifetime {
+				continue
+			}
+			delete(accountTxs, nonce)
+			delete(mp.addedAt, tx.Hash)
+			expired++
+			fmt.Printf("[Mempool] Future tx %s expired\n", tx.Hash)
+		}
+		if len(accountTxs) == 0 {
+			delete(mp.future, from)
+		}
+	}
+	return expired
+}
+
+// 멤풀에 없는 트랜잭션의 시각 기록 정리
+func (mp *Mempool) pruneAddedAt() {
+	remaining := make(map[string]bool, len(mp.addedAt))
+	for _, queue := range []map[string]map[uint64]Transaction{mp.pending, mp.future} {
+		for _, accountTxs := range queue {
+			for _, tx := range accountTxs {
+				remaining[tx.Hash] = true
+			}
+		}
+	}
+	for hash := range mp.addedAt {
+		if !remaining[hash] {
+			delete(mp.addedAt, hash)
+		}
+	}
+}
+
+// 큐에서 트랜잭션 하나 제거 (락을 잡은 상태에서 호출)
+func (mp *Mempool) dropTransaction(queue map[string]map[uint64]Transaction, tx Transaction) {
+	delete(queue[tx.From], tx.Nonce)
+	if len(queue[tx.From]) == 0 {
+		delete(queue, tx.From)
+	}
+	delete(mp.addedAt, tx.Hash)
+}
+
+// 주소의 가장 높은 nonce 트랜잭션
+func highestNonceTx(accountTxs map[uint64]Transaction) Transaction {
+	var last Transaction
+	first := true
+	for nonce, tx := range accountTxs {
+		if first || nonce > last.Nonce {
+			last = tx
+			first = false
+		}
+	}
+	return last
+}
+
+// a가 b보다 먼저 밀려나야 하면 true : 수수료가 낮을수록, 같으면 nonce가 높을수록
+func lowerPriority(a, b Transaction) bool {
+	if cmp := a.FeeOrZero().Cmp(b.FeeOrZero()); cmp != 0 {
+		return cmp < 0
+	}
+	if a.Nonce != b.Nonce {
+		return a.Nonce > b.Nonce
+	}
+	return a.Hash > b.Hash
+}
@@ -0,0 +1,70 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"simple_p2p_client/account"
+)
+
+// 멤풀 pending 트랜잭션을 모두 실행했다고 가정한 계정 상태
+// Nonce는 DB의 Account.Nonce와 같은 의미 (마지막으로 사용된 nonce), 다음 트랜잭션의 nonce는 Nonce + 1
+type PendingAccountState struct {
+	Nonce   uint64
+	Balance *big.Int
+}
+
+// DB 계정 상태에 pending 트랜잭션을 nonce 순으로 적용 (nonce가 끊기면 중단), 락을 잡은 상태에서 호출
+func (mp *Mempool) pendingStateLocked(address string, committed *account.Account) PendingAccountState {
+	state := PendingAccountState{
+		Nonce:   committed.Nonce,
+		Balance: new(big.Int).Set(committed.Balance),
+	}
+
+	pendingTxs := mp.pending[address]
+	for {
+		tx, exists := pendingTxs[state.Nonce+1]
+		if !exists {
+			break
+		}
+		state.Balance.Sub(state.Balance, tx.Cost())
+		state.Nonce++
+	}
+	return state
+}
+
+// 주소의 pending 상태 조회
+func (mp *Mempool) PendingState(address string) (PendingAccountState, error) {
+	committed, err := committedAccount(address)
+	if err != nil {
+		return PendingAccountState{}, err
+	}
+
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	return mp.pendingStateLocked(address, committed), nil
+}
+
+// 기본 멤풀 기준 주소의 pending 상태 (RPC 조회용)
+func GetPendingAccountState(address string) (PendingAccountState, error) {
+	if defaultMempool == nil {
+		committed, err := committedAccount(address)
+		if err != nil {
+			return PendingAccountState{}, err
+		}
+		return PendingAccountState{Nonce: committed.Nonce, Balance: committed.Balance}, nil
+	}
+	return defaultMempool.PendingState(address)
+}
+
+// DB의 계정 상태, 없는 계정은 잔액, nonce 0
+func committedAccount(address string) (*account.Account, error) {
+	exists, err := account.AccountExists(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check account existence : %v", err)
+	}
+	if !exists {
+		return &account.Account{Balance: big.NewInt(0), Nonce: 0}, nil
+	}
+	return account.GetAccount(address)
+}
@@ -0,0 +1,129 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"simple_p2p_client/leveldb"
+
+	db "github.com/syndtr/goleveldb/leveldb"
+)
+
+// 블록 본문 정리
+// 정리한 블록은 헤더(번호, 부모 해시, 머클루트, miner, 타임스탬프, 보상)만 남고 트랜잭션 목록이 비어 있음
+// 블록 해시는 머클루트로 계산하므로 헤더만으로도 체인을 따라갈 수 있음
+// prune:tail : 본문이 남아 있는 가장 오래된 블록 번호 (이전 블록은 모두 정리됨)
+const pruneTailKey = "prune:tail"
+
+// 본문을 남길 최근 블록 수 (0이면 정리하지 않음)
+var pruneBlocks uint64
+
+// 블록 본문 정리 범위 설정 (노드 시작 시 설정으로 지정)
+func SetPruneBlocks(blocks uint64) {
+	pruneBlocks = blocks
+}
+
+// 본문이 정리된 블록인지 (트랜잭션이 있던 블록만 해당)
+func IsBodyPruned(block *Block) bool {
+	return len(block.Transaction) == 0 && block.MerkleRoot != "0x0"
+}
+
+// 본문이 남아 있는 가장 오래된 블록 번호 (정리한 적이 없으면 1)
+func GetPruneTail() (uint64, error) {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get dbinstance : %v", err)
+	}
+	return pruneTail(dbInstance)
+}
+
+func pruneTail(dbInstance *db.DB) (uint64, error) {
+	data, err := dbInstance.Get([]byte(pruneTailKey), nil)
+	if errors.Is(err, db.ErrNotFound) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	tail, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid prune tail : %s", data)
+	}
+	return tail, nil
+}
+
+// 노드 시작 시 현재 헤드 기준으로 보관 범위를 벗어난 상태 이력, 블록 본문 정리
+func PruneChain() error {
+	chainMu.Lock()
+	defer chainMu.Unlock()
+
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return err
+	}
+	head, err := GetLatestBlock()
+	if err != nil {
+		return err
+	}
+	if stateHistoryWindow > 0 && head.Number > stateHistoryWindow {
+		if err := pruneStateHistory(dbInstance, head.Number-stateHistoryWindow); err != nil {
+			return err
+		}
+	}
+	return pruneOldBlocks(head.Number)
+}
+
+// 새 헤드 기준으로 보관 범위를 벗어난 블록 본문 정리 (chainMu를 잡은 상태에서 호출)
+func pruneOldBlocks(head uint64) error {
+	if pruneBlocks == 0 || head <= pruneBlocks {
+		return nil
+	}
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return err
+	}
+	return pruneBlockBodies(dbInstance, head-pruneBlocks)
+}
+
+// cutoff 이전 블록의 트랜잭션, 트랜잭션 위치 인덱스, 주소 인덱스 삭제
+func pruneBlockBodies(dbInstance *db.DB, cutoff uint64) error {
+	tail, err := pruneTail(dbInstance)
+	if err != nil {
+		return err
+	}
+
+	pruned := 0
+	for number := tail; number < cutoff; number++ {
+		batch := new(db.Batch)
+		block, err := GetBlockByNumber(number)
+		switch {
+		case errors.Is(err, db.ErrNotFound):
+			// 스냅샷 이전 블록
+		case err != nil:
+			return fmt.Errorf("failed to read block %d : %v", number, err)
+		case len(block.Transaction) > 0:
+			for i, tx := range block.Transaction {
+				batch.Delete(txLookupKey(tx.Hash))
+				batch.Delete(addressTxKey(tx.From, block.Number, i))
+				batch.Delete(addressTxKey(tx.To, block.Number, i))
+			}
+			block.Transaction = []Transaction{}
+			blockJSON, err := json.Marshal(block)
+			if err != nil {
+				return err
+			}
+			batch.Put([]byte(block.Hash), blockJSON)
+			pruned++
+		}
+		batch.Put([]byte(pruneTailKey), []byte(strconv.FormatUint(number+1, 10)))
+		if err := dbInstance.Write(batch, nil); err != nil {
+			return fmt.Errorf("failed to prune block %d : %v", number, err)
+		}
+	}
+	if pruned > 0 {
+		fmt.Printf("[BLOCK] Pruned bodies of %d blocks before block %d\n", pruned, cutoff)
+	}
+	return nil
+}
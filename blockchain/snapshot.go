@@ -0,0 +1,380 @@
+package blockchain
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"simple_p2p_client/account"
+	"simple_p2p_client/leveldb"
+	"simple_p2p_client/mediator"
+	"simple_p2p_client/utils"
+
+	db "github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// 스냅샷으로 복원한 체인의 시작 블록 (이전 블록은 DB에 없음)
+const snapshotBaseKey = "snapshot:base"
+
+// 스냅샷의 계정 하나
+type SnapshotAccount struct {
+	Address string   `json:"address"`
+	Balance *big.Int `json:"balance"`
+	Nonce   uint64   `json:"nonce"`
+}
+
+// 블록 N 시점의 전체 계정 상태 (gzip JSON 파일로 저장)
+type Snapshot struct {
+	Number       uint64            `json:"number"`
+	BlockHash    string            `json:"blockHash"`
+	StateRoot    string            `json:"stateRoot"` // 주소 순으로 정렬한 계정의 머클루트
+	Supply       *big.Int          `json:"supply"`
+	Genesis      GenesisConfig     `json:"genesis"`
+	GenesisBlock *Block            `json:"genesisBlock"`
+	Head         *Block            `json:"head"`
+	Accounts     []SnapshotAccount `json:"accounts"`
+}
+
+// 스냅샷 기준 블록
+type snapshotBase struct {
+	Number uint64 `json:"number"`
+	Hash   string `json:"hash"`
+}
+
+// 현재 헤드 블록 시점의 상태로 스냅샷 생성
+// 블록 실행 중이 아닐 때 LevelDB 스냅샷을 잡아 헤드 블록과 계정이 항상 같은 시점
+func CreateSnapshot() (*Snapshot, error) {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dbinstance : %v", err)
+	}
+
+	chainMu.Lock()
+	dbSnapshot, err := dbInstance.GetSnapshot()
+	chainMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get db snapshot : %v", err)
+	}
+	defer dbSnapshot.Release()
+
+	readBlock := func(key []byte) (*Block, error) {
+		blockJSON, err := dbSnapshot.Get(key, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read block %s : %w", key, err)
+		}
+		var block Block
+		if err := json.Unmarshal(blockJSON, &block); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal block : %v", err)
+		}
+		return &block, nil
+	}
+
+	head, err := readBlock([]byte("lastblock"))
+	if err != nil {
+		return nil, err
+	}
+	genesisHash, err := dbSnapshot.Get(blockNumberKey(1), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read genesis block index : %v", err)
+	}
+	genesisBlock, err := readBlock(genesisHash)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		Number:       head.Number,
+		BlockHash:    head.Hash,
+		Supply:       big.NewInt(0),
+		Genesis:      DefaultGenesis(),
+		GenesisBlock: genesisBlock,
+		Head:         head,
+		Accounts:     []SnapshotAccount{},
+	}
+
+	if configJSON, err := dbSnapshot.Get([]byte(genesisConfigKey), nil); err == nil {
+		if err := json.Unmarshal(configJSON, &snapshot.Genesis); err != nil {
+			return nil, fmt.Errorf("failed to parse stored genesis config : %v", err)
+		}
+	}
+	if supplyData, err := dbSnapshot.Get([]byte(totalSupplyKey), nil); err == nil {
+		if _, ok := snapshot.Supply.SetString(string(supplyData), 10); !ok {
+			return nil, fmt.Errorf("invalid total supply value : %s", supplyData)
+		}
+	}
+
+	iter := dbSnapshot.NewIterator(util.BytesPrefix([]byte("account:")), nil)
+	for iter.Next() {
+		var accountData account.Account
+		if err := json.Unmarshal(iter.Value(), &accountData); err != nil {
+			iter.Release()
+			return nil, fmt.Errorf("failed to parse account %s : %v", iter.Key(), err)
+		}
+		if accountData.Balance == nil {
+			accountData.Balance = big.NewInt(0)
+		}
+		snapshot.Accounts = append(snapshot.Accounts, SnapshotAccount{
+			Address: strings.TrimPrefix(string(iter.Key()), "account:"),
+			Balance: accountData.Balance,
+			Nonce:   accountData.Nonce,
+		})
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	// 키 순서로 읽었으므로 이미 주소 순
+	snapshot.StateRoot, err = computeStateRoot(snapshot.Accounts)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// 계정 목록의 머클루트 (계정이 없으면 0x0)
+func computeStateRoot(accounts []SnapshotAccount) (string, error) {
+	if len(accounts) == 0 {
+		return "0x0", nil
+	}
+	leaves := make([]string, len(accounts))
+	for i, acc := range accounts {
+		leaves[i] = utils.Keccak256Hex([]byte(fmt.Sprintf("%s:%s:%d", acc.Address, acc.Balance, acc.Nonce)))
+	}
+	return BuildMerkleTree(leaves)
+}
+
+// 스냅샷 자체 검증 : 헤드, 제네시스 블록 해시와 상태 머클루트
+func (s *Snapshot) Verify() error {
+	if s.Head == nil || s.GenesisBlock == nil || s.Supply == nil {
+		return fmt.Errorf("incomplete snapshot")
+	}
+	if s.Head.Number != s.Number || s.Head.Hash != s.BlockHash {
+		return fmt.Errorf("snapshot head is block %d (%s), expected %d (%s)", s.Head.Number, s.Head.Hash, s.Number, s.BlockHash)
+	}
+	if err := verifyBlockHash(s.Head); err != nil {
+		return fmt.Errorf("snapshot head : %v", err)
+	}
+	if s.GenesisBlock.Number != 1 {
+		return fmt.Errorf("snapshot genesis block does not match the genesis config")
+	}
+	if err := s.Genesis.checkGenesisBlock(s.GenesisBlock); err != nil {
+		return fmt.Errorf("snapshot genesis block does not match the genesis config : %v", err)
+	}
+	if err := verifyBlockHash(s.GenesisBlock); err != nil {
+		return fmt.Errorf("snapshot genesis block : %v", err)
+	}
+
+	for i := 1; i < len(s.Accounts); i++ {
+		if s.Accounts[i-1].Address >= s.Accounts[i].Address {
+			return fmt.Errorf("snapshot accounts are not sorted or contain duplicates at %s", s.Accounts[i].Address)
+		}
+	}
+	for _, acc := range s.Accounts {
+		if acc.Balance == nil || acc.Balance.Sign() < 0 {
+			return fmt.Errorf("invalid balance for account %s", acc.Address)
+		}
+	}
+
+	stateRoot, err := computeStateRoot(s.Accounts)
+	if err != nil {
+		return err
+	}
+	if stateRoot != s.StateRoot {
+		return fmt.Errorf("state root mismatch : expected %s, got %s", s.StateRoot, stateRoot)
+	}
+	return nil
+}
+
+// 빈 데이터 디렉토리에 스냅샷 복원 : 계정, 총 발행량, 제네시스 설정, 제네시스와 헤드 블록
+// 복원한 노드는 헤드 다음 블록부터 받은 블록, 가져온 블록을 처리
+func RestoreSnapshot(s *Snapshot) error {
+	if err := s.Verify(); err != nil {
+		return fmt.Errorf("invalid snapshot : %v", err)
+	}
+
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return fmt.Errorf("failed to get dbinstance : %v", err)
+	}
+	if _, err := dbInstance.Get([]byte("lastblock"), nil); err == nil {
+		return fmt.Errorf("data directory already contains a chain")
+	}
+
+	batch := new(db.Batch)
+	for _, acc := range s.Accounts {
+		accountJSON, err := json.Marshal(account.Account{Balance: acc.Balance, Nonce: acc.Nonce})
+		if err != nil {
+			return err
+		}
+		batch.Put([]byte("account:"+acc.Address), accountJSON)
+	}
+
+	for _, block := range []*Block{s.GenesisBlock, s.Head} {
+		blockJSON, err := json.Marshal(block)
+		if err != nil {
+			return err
+		}
+		batch.Put([]byte(block.Hash), blockJSON)
+		if err := putBlockIndexes(batch, block); err != nil {
+			return err
+		}
+		if block == s.Head {
+			batch.Put([]byte("lastblock"), blockJSON)
+		}
+	}
+
+	configJSON, err := json.Marshal(s.Genesis)
+	if err != nil {
+		return err
+	}
+	baseJSON, err := json.Marshal(snapshotBase{Number: s.Number, Hash: s.BlockHash})
+	if err != nil {
+		return err
+	}
+	batch.Put([]byte(genesisConfigKey), configJSON)
+	batch.Put([]byte(totalSupplyKey), []byte(s.Supply.String()))
+	batch.Put([]byte(snapshotBaseKey), baseJSON)
+
+	if err := dbInstance.Write(batch, nil); err != nil {
+		return fmt.Errorf("failed to write snapshot : %v", err)
+	}
+	chainConfig = s.Genesis
+	return nil
+}
+
+// 스냅샷으로 복원한 체인이면 시작 블록 반환
+func getSnapshotBase() (*snapshotBase, error) {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return nil, err
+	}
+	data, err := dbInstance.Get([]byte(snapshotBaseKey), nil)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var base snapshotBase
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, fmt.Errorf("invalid snapshot base : %v", err)
+	}
+	return &base, nil
+}
+
+// 스냅샷 파일 이름 : snapshot-<번호>-<해시 앞 8자리>.json.gz
+func SnapshotFileName(s *Snapshot) string {
+	hash := s.BlockHash
+	if len(hash) > 8 {
+		hash = hash[:8]
+	}
+	return fmt.Sprintf("snapshot-%d-%s.json.gz", s.Number, hash)
+}
+
+// 스냅샷을 gzip JSON 파일로 저장 (임시 파일에 쓴 뒤 이름 변경)
+func WriteSnapshotFile(path string, s *Snapshot) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory : %v", err)
+	}
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file : %v", err)
+	}
+
+	gz := gzip.NewWriter(file)
+	err = json.NewEncoder(gz).Encode(s)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write snapshot file : %v", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// gzip JSON 스냅샷 파일 읽기
+func ReadSnapshotFile(path string) (*Snapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot file : %v", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot file : %v", err)
+	}
+	defer gz.Close()
+
+	var snapshot Snapshot
+	if err := json.NewDecoder(gz).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot file : %v", err)
+	}
+	return &snapshot, nil
+}
+
+// interval 블록마다 dir에 스냅샷 저장, 최근 keep개만 남김 (interval이 0이면 사용하지 않음)
+func StartSnapshotter(dir string, interval uint64, keep int) {
+	if interval == 0 {
+		return
+	}
+	sub := mediator.GetMediatorInstance().Subscribe(10, mediator.PolicyDrop, mediator.ChainHeadEventType)
+
+	go func() {
+		for event := range sub.Events() {
+			head, ok := event.(mediator.ChainHeadEvent)
+			if !ok || head.Number%interval != 0 {
+				continue
+			}
+
+			snapshot, err := CreateSnapshot()
+			if err != nil {
+				fmt.Printf("[SNAPSHOT] Failed to create snapshot : %v\n", err)
+				continue
+			}
+			path := filepath.Join(dir, SnapshotFileName(snapshot))
+			if err := WriteSnapshotFile(path, snapshot); err != nil {
+				fmt.Printf("[SNAPSHOT] %v\n", err)
+				continue
+			}
+			fmt.Printf("[SNAPSHOT] Saved block %d state (%d accounts) : %s\n", snapshot.Number, len(snapshot.Accounts), path)
+			pruneSnapshots(dir, keep)
+		}
+	}()
+}
+
+// 오래된 스냅샷 파일 삭제 (블록 번호가 큰 keep개만 남김)
+func pruneSnapshots(dir string, keep int) {
+	if keep <= 0 {
+		return
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "snapshot-*.json.gz"))
+	if err != nil || len(paths) <= keep {
+		return
+	}
+
+	number := func(path string) uint64 {
+		var n uint64
+		fmt.Sscanf(filepath.Base(path), "snapshot-%d-", &n)
+		return n
+	}
+	sort.Slice(paths, func(i, j int) bool { return number(paths[i]) > number(paths[j]) })
+	for _, path := range paths[keep:] {
+		if err := os.Remove(path); err != nil {
+			fmt.Printf("[SNAPSHOT] Failed to remove old snapshot %s : %v\n", path, err)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"simple_p2p_client/account"
+	"simple_p2p_client/leveldb"
+
+	db "github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// 계정 상태 이력
+// history:<주소>:<블록 번호> : 해당 블록 실행 후 계정 상태 (블록에서 바뀐 계정만 기록)
+// changes:<블록 번호>        : 블록에서 바뀐 주소 목록 (오래된 이력 정리에 사용)
+// history:start             : 이력이 시작된 블록 (이 블록에는 모든 계정을 기록)
+const historyStartKey = "history:start"
+
+// 이력을 남길 최근 블록 수 (0이면 아카이브 노드 : 모든 블록의 상태 유지)
+var stateHistoryWindow uint64
+
+// 상태 이력 보관 범위 설정 (노드 시작 시 설정으로 지정)
+func SetStateHistoryWindow(blocks uint64) {
+	stateHistoryWindow = blocks
+}
+
+// 블록 번호는 20자리로 채워 키 순서와 번호 순서를 맞춤
+func historyKey(address string, number uint64) []byte {
+	return []byte(fmt.Sprintf("history:%s:%020d", address, number))
+}
+
+func historyPrefix(address string) []byte {
+	return []byte("history:" + address + ":")
+}
+
+func changesKey(number uint64) []byte {
+	return []byte(fmt.Sprintf("changes:%020d", number))
+}
+
+// 블록에서 상태가 바뀔 수 있는 계정 (from, to, miner), 주소 순
+func touchedAccounts(block *Block) []string {
+	touched := map[string]bool{block.Miner: true}
+	for _, tx := range block.Transaction {
+		touched[tx.From] = true
+		touched[tx.To] = true
+	}
+
+	addresses := make([]string, 0, len(touched))
+	for address := range touched {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// 이력이 없는 체인이면 현재 헤드 블록 시점의 모든 계정을 기록해 이력 시작
+// 새 체인은 제네시스 블록부터, 예전 체인, 스냅샷으로 복원한 체인은 이후 블록부터 조회 가능
+func initStateHistory(dbInstance *db.DB) error {
+	if _, err := dbInstance.Get([]byte(historyStartKey), nil); err == nil {
+		return nil
+	} else if !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+
+	head, err := GetLatestBlock()
+	if err != nil {
+		return err
+	}
+
+	batch := new(db.Batch)
+	addresses := []string{}
+	iter := dbInstance.NewIterator(util.BytesPrefix([]byte("account:")), nil)
+	for iter.Next() {
+		address := strings.TrimPrefix(string(iter.Key()), "account:")
+		batch.Put(historyKey(address, head.Number), append([]byte{}, iter.Value()...))
+		addresses = append(addresses, address)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	addressesJSON, err := json.Marshal(addresses)
+	if err != nil {
+		return err
+	}
+	batch.Put(changesKey(head.Number), addressesJSON)
+	batch.Put([]byte(historyStartKey), []byte(strconv.FormatUint(head.Number, 10)))
+	if err := dbInstance.Write(batch, nil); err != nil {
+		return fmt.Errorf("failed to start state history : %v", err)
+	}
+	fmt.Printf("[STATE] History starts at block %d (%d accounts)\n", head.Number, len(addresses))
+	return nil
+}
+
+// 블록 실행, 보상 지급 후 바뀐 계정의 상태 기록 (chainMu를 잡은 상태에서 호출)
+func recordStateChanges(block *Block) error {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return err
+	}
+
+	addresses := touchedAccounts(block)
+	batch := new(db.Batch)
+	for _, address := range addresses {
+		accountJSON, err := dbInstance.Get([]byte("account:"+address), nil)
+		if err != nil {
+			return fmt.Errorf("failed to read account %s : %v", address, err)
+		}
+		batch.Put(historyKey(address, block.Number), accountJSON)
+	}
+	addressesJSON, err := json.Marshal(addresses)
+	if err != nil {
+		return err
+	}
+	batch.Put(changesKey(block.Number), addressesJSON)
+	if err := dbInstance.Write(batch, nil); err != nil {
+		return fmt.Errorf("failed to write state history : %v", err)
+	}
+
+	if stateHistoryWindow > 0 && block.Number > stateHistoryWindow {
+		return pruneStateHistory(dbInstance, block.Number-stateHistoryWindow)
+	}
+	return nil
+}
+
+// cutoff 이전 블록의 이력 정리 : cutoff 이후 블록 조회에 필요한 (cutoff 시점에 가장 최근인) 기록은 남김
+// changes:<n>이 cutoff 이전이 되면 n에서 바뀐 주소의 n 이전 기록은 더 이상 필요 없음
+// n의 기록은 그 주소가 다시 바뀐 블록이 cutoff 이전이 될 때 같은 방식으로 삭제
+func pruneStateHistory(dbInstance *db.DB, cutoff uint64) error {
+	iter := dbInstance.NewIterator(&util.Range{Start: changesKey(0), Limit: changesKey(cutoff)}, nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		number, err := strconv.ParseUint(strings.TrimPrefix(string(iter.Key()), "changes:"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid changes key %s", iter.Key())
+		}
+		var addresses []string
+		if err := json.Unmarshal(iter.Value(), &addresses); err != nil {
+			return fmt.Errorf("invalid changes entry for block %d : %v", number, err)
+		}
+
+		batch := new(db.Batch)
+		for _, address := range addresses {
+			older := dbInstance.NewIterator(&util.Range{Start: historyPrefix(address), Limit: historyKey(address, number)}, nil)
+			for older.Next() {
+				batch.Delete(append([]byte{}, older.Key()...))
+			}
+			older.Release()
+			if err := older.Error(); err != nil {
+				return err
+			}
+		}
+		batch.Delete(iter.Key())
+		if err := dbInstance.Write(batch, nil); err != nil {
+			return fmt.Errorf("failed to prune state history : %v", err)
+		}
+	}
+	return iter.Error()
+}
+
+// 조회할 수 있는 가장 오래된 블록 (이력 시작 블록, 보관 범위 중 늦은 쪽)
+func oldestStateBlock(dbInstance *db.DB, head uint64) (uint64, error) {
+	data, err := dbInstance.Get([]byte(historyStartKey), nil)
+	if errors.Is(err, db.ErrNotFound) {
+		return head, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	oldest, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid history start : %s", data)
+	}
+	if stateHistoryWindow > 0 && head > stateHistoryWindow && head-stateHistoryWindow > oldest {
+		oldest = head - stateHistoryWindow
+	}
+	return oldest, nil
+}
+
+// 과거 상태를 조회할 수 있는 가장 오래된 블록 번호
+func GetOldestStateBlock() (uint64, error) {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get dbinstance : %v", err)
+	}
+	head, err := GetLatestBlock()
+	if err != nil {
+		return 0, err
+	}
+	return oldestStateBlock(dbInstance, head.Number)
+}
+
+// 블록 number 실행 후의 계정 상태 (그 시점에 없던 계정은 db.ErrNotFound)
+func GetAccountAt(address string, number uint64) (*account.Account, error) {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dbinstance : %v", err)
+	}
+	head, err := GetLatestBlock()
+	if err != nil {
+		return nil, err
+	}
+	if number > head.Number {
+		return nil, fmt.Errorf("block %d is beyond the chain head %d", number, head.Number)
+	}
+	if number == head.Number {
+		return account.GetAccount(address)
+	}
+
+	oldest, err := oldestStateBlock(dbInstance, head.Number)
+	if err != nil {
+		return nil, err
+	}
+	if number < oldest {
+		return nil, fmt.Errorf("state at block %d is not available, oldest available block is %d", number, oldest)
+	}
+
+	// number 이하에서 가장 최근 기록
+	iter := dbInstance.NewIterator(&util.Range{Start: historyPrefix(address), Limit: historyKey(address, number+1)}, nil)
+	defer iter.Release()
+	if !iter.Last() {
+		if err := iter.Error(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("account %s not found at block %d : %w", address, number, db.ErrNotFound)
+	}
+
+	var accountData account.Account
+	if err := json.Unmarshal(iter.Value(), &accountData); err != nil {
+		return nil, fmt.Errorf("invalid state history entry %s : %v", iter.Key(), err)
+	}
+	if accountData.Balance == nil {
+		accountData.Balance = big.NewInt(0)
+	}
+	return &accountData, nil
+}
@@ -0,0 +1,163 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"simple_p2p_client/account"
+	"simple_p2p_client/constants"
+	"sort"
+	"strings"
+
+	db "github.com/syndtr/goleveldb/leveldb"
+)
+
+// 블록 검증용 메모리 상태 : 처음 접근할 때 DB 계정을 복사해 오고, 변경은 메모리에만 기록
+type stateOverlay struct {
+	accounts map[string]*account.Account
+	load     func(address string) (*account.Account, error)
+	reward   RewardSchedule // 수수료 소각 비율, 보상 계산에 사용할 보상 정책
+}
+
+func newStateOverlay() *stateOverlay {
+	return &stateOverlay{
+		accounts: make(map[string]*account.Account),
+		load:     committedAccount,
+		reward:   chainConfig.Reward,
+	}
+}
+
+func (s *stateOverlay) get(address string) (*account.Account, error) {
+	if cached, exists := s.accounts[address]; exists {
+		return cached, nil
+	}
+
+	committed, err := s.load(address)
+	if err != nil {
+		return nil, err
+	}
+	copied := &account.Account{Balance: new(big.Int).Set(committed.Balance), Nonce: committed.Nonce}
+	s.accounts[address] = copied
+	return copied, nil
+}
+
+// 메모리 상태의 계정을 배치에 추가 (주소 순)
+func (s *stateOverlay) putAccounts(batch *db.Batch) error {
+	addresses := make([]string, 0, len(s.accounts))
+	for address := range s.accounts {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		accountJSON, err := json.Marshal(s.accounts[address])
+		if err != nil {
+			return fmt.Errorf("failed to serialize account %s : %v", address, err)
+		}
+		batch.Put([]byte("account:"+address), accountJSON)
+	}
+	return nil
+}
+
+// 트랜잭션 하나를 메모리 상태에 실행 (블록 검증, 실행 공통 : from 차감, to 입금, 소각 몫을 뺀 수수료는 miner에게)
+func (s *stateOverlay) applyTransaction(tx Transaction, minerAddress string) error {
+	from, err := s.get(tx.From)
+	if err != nil {
+		return fmt.Errorf("failed to load account %s : %v", tx.From, err)
+	}
+
+	// nonce는 정확히 다음 번호여야 함 (빈 번호, 재사용 불가)
+	if tx.Nonce <= from.Nonce {
+		return fmt.Errorf("%w : expected %d, got %d", account.ErrNonceTooLow, from.Nonce+1, tx.Nonce)
+	}
+	if tx.Nonce != from.Nonce+1 {
+		return fmt.Errorf("nonce gap : expected %d, got %d", from.Nonce+1, tx.Nonce)
+	}
+
+	cost := tx.Cost()
+	if from.Balance.Cmp(cost) < 0 {
+		return fmt.Errorf("%w : balance is %s, value + fee is %s", account.ErrInsufficientFunds, from.Balance, cost)
+	}
+	from.Balance.Sub(from.Balance, cost)
+	from.Nonce++
+
+	to, err := s.get(tx.To)
+	if err != nil {
+		return fmt.Errorf("failed to load account %s : %v", tx.To, err)
+	}
+	to.Balance.Add(to.Balance, tx.Value)
+
+	minerFee := new(big.Int).Sub(tx.FeeOrZero(), s.reward.BurnedFee(tx.FeeOrZero()))
+	if minerFee.Sign() > 0 {
+		miner, err := s.get(minerAddress)
+		if err != nil {
+			return fmt.Errorf("failed to load miner account %s : %v", minerAddress, err)
+		}
+		miner.Balance.Add(miner.Balance, minerFee)
+	}
+	return nil
+}
+
+// 블록 안 트랜잭션을 순서대로 메모리 상태에 실행하며 검증 (DB는 변경하지 않음)
+func validateBlockTransactions(block *Block, overlay *stateOverlay) error {
+	if len(block.Transaction) > constants.MaxTransactionsPerBlock {
+		return fmt.Errorf("too many transactions : %d, max %d", len(block.Transaction), constants.MaxTransactionsPerBlock)
+	}
+	if len(block.Transaction) > 0 && !account.IsValidAddress(block.Miner) {
+		return fmt.Errorf("invalid miner address : %s", block.Miner)
+	}
+
+	seen := make(map[string]bool, len(block.Transaction))
+	for i, tx := range block.Transaction {
+		// 1. 중복 트랜잭션
+		hash := strings.ToLower(tx.Hash)
+		if seen[hash] {
+			return fmt.Errorf("duplicate transaction %s at index %d", tx.Hash, i)
+		}
+		seen[hash] = true
+
+		// 2. 필드, 해시, 서명 검증
+		if err := verifyTransaction(tx); err != nil {
+			return fmt.Errorf("transaction %s at index %d : %v", tx.Hash, i, err)
+		}
+
+		// 3. 앞 트랜잭션까지 반영된 상태에서 nonce, 잔액 검증 후 실행
+		if err := overlay.applyTransaction(tx, block.Miner); err != nil {
+			return fmt.Errorf("transaction %s at index %d : %v", tx.Hash, i, err)
+		}
+	}
+	return nil
+}
+
+// 상태와 무관한 트랜잭션 검증 : 필드, 해시, 서명
+func verifyTransaction(tx Transaction) error {
+	if tx.Value == nil {
+		return fmt.Errorf("missing value")
+	}
+	if err := ValidateTransactionFields(tx.From, tx.To, tx.Value.String(), tx.Signature, tx.Nonce, tx.Fee); err != nil {
+		return fmt.Errorf("field validation failed : %v", err)
+	}
+
+	expected, _, err := CreateTransaction(tx.From, tx.To, tx.Signature, tx.Value, tx.Nonce, tx.Fee)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(expected.Hash, tx.Hash) {
+		return fmt.Errorf("hash mismatch : expected %s", expected.Hash)
+	}
+
+	messageHash := TransactionSigningHash(tx.From, tx.To, tx.Value, tx.Nonce, tx.Fee)
+	decodedSignature, err := hex.DecodeString(tx.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature format : %v", err)
+	}
+	isValidSig, err := VerifySignature(messageHash, decodedSignature, tx.From)
+	if err != nil {
+		return fmt.Errorf("signature verification failed : %v", err)
+	}
+	if !isValidSig {
+		return fmt.Errorf("signature verification failed : signer is not %s", tx.From)
+	}
+	return nil
+}
@@ -6,13 +6,12 @@ import (
 	"fmt"
 	"math/big"
 	"simple_p2p_client/account"
-	"simple_p2p_client/leveldb"
+	"simple_p2p_client/mediator"
 	"simple_p2p_client/utils"
 	"strconv"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/crypto"
-	db "github.com/syndtr/goleveldb/leveldb"
 )
 
 type RawTransaction struct {
@@ -20,6 +19,7 @@ type RawTransaction struct {
 	To        string   `json:"to"`
 	Value     *big.Int `json:"value"`
 	Nonce     uint64   `json:"nonce"`
+	Fee       *big.Int `json:"fee,omitempty"` // 블록 Miner에게 지급되는 수수료 (없으면 0)
 	Signature string   `json:"signature"`
 }
 
@@ -29,9 +29,37 @@ type Transaction struct {
 	To        string   `json:"to"`
 	Value     *big.Int `json:"value"`
 	Nonce     uint64   `json:"nonce"`
+	Fee       *big.Int `json:"fee,omitempty"`
 	Signature string   `json:"signature"`
 }
 
+// 수수료 (nil이면 0)
+func (tx Transaction) FeeOrZero() *big.Int {
+	return feeOrZero(tx.Fee)
+}
+
+// value + fee : 보내는 계정에서 빠져나가는 총액
+func (tx Transaction) Cost() *big.Int {
+	return new(big.Int).Add(tx.Value, tx.FeeOrZero())
+}
+
+func feeOrZero(fee *big.Int) *big.Int {
+	if fee == nil {
+		return big.NewInt(0)
+	}
+	return fee
+}
+
+// 서명할 메시지의 Keccak256 해시
+// 메시지는 from + to + value + nonce, 수수료가 있으면 뒤에 ":" + fee를 붙임 (수수료 없는 기존 서명과 호환)
+func TransactionSigningHash(from, to string, value *big.Int, nonce uint64, fee *big.Int) []byte {
+	message := fmt.Sprintf("%s%s%s%d", from, to, value.String(), nonce)
+	if fee != nil && fee.Sign() > 0 {
+		message = fmt.Sprintf("%s:%s", message, fee.String())
+	}
+	return utils.Keccak256([]byte(message))
+}
+
 // 서명 검증
 func VerifySignature(messageHash []byte, signature []byte, fromAddress string) (bool, error) {
 
@@ -62,7 +90,7 @@ func VerifySignature(messageHash []byte, signature []byte, fromAddress string) (
 }
 
 // 트랜잭션 필드들 유효성 검증 (주소 양식, 빈 값, value 크기)
-func ValidateTransactionFields(from, to, value, signature string, nonce uint64) error {
+func ValidateTransactionFields(from, to, value, signature string, nonce uint64, fee *big.Int) error {
 
 	// 1. 빈 인자 없는지 확인
 	if from == "" || to == "" || value == "" || signature == "" {
@@ -81,6 +109,11 @@ func ValidateTransactionFields(from, to, value, signature string, nonce uint64)
 		return fmt.Errorf("inavlid nonce : must be a non-negative integer")
 	}
 
+	// 3-1. fee >= 0
+	if fee != nil && fee.Sign() < 0 {
+		return fmt.Errorf("invalid fee : must be a non-negative integer")
+	}
+
 	// 4. from, to 주소 양식이 올바른지
 	if !account.IsValidAddress(from) {
 		return fmt.Errorf("invalid address : address 'from' format is wrong")
@@ -94,13 +127,19 @@ func ValidateTransactionFields(from, to, value, signature string, nonce uint64)
 }
 
 // 트랜잭션 각 필드들을 조합해 트랜잭션 구조체, json 형태 반환
-func CreateTransaction(from, to, signature string, value *big.Int, nonce uint64) (Transaction, string, error) {
+func CreateTransaction(from, to, signature string, value *big.Int, nonce uint64, fee *big.Int) (Transaction, string, error) {
+	// 수수료 0은 없는 것으로 취급 (수수료 없는 트랜잭션과 해시가 같도록)
+	if fee != nil && fee.Sign() == 0 {
+		fee = nil
+	}
+
 	// 1. RawTransaction 생성
 	rawTransaction := RawTransaction{
 		From:      from,
 		To:        to,
 		Value:     value,
 		Nonce:     nonce,
+		Fee:       fee,
 		Signature: signature,
 	}
 
@@ -122,163 +161,84 @@ func CreateTransaction(from, to, signature string, value *big.Int, nonce uint64)
 		To:        to,
 		Value:     value,
 		Nonce:     nonce,
+		Fee:       fee,
 		Signature: signature,
 	}
 
 	return fullTransaction, string(jsonRawTransaction), nil
 }
 
-// 트랜잭션 유효성 검증, 멤풀에 저장, Json 트랜잭션 반환
-func ProcessTransaction(rawTransactionMessage string) (string, error) {
+// 트랜잭션 유효성 검증, 멤풀에 저장, 트랜잭션 해시와 Json 트랜잭션 반환
+func ProcessTransaction(rawTransactionMessage string) (string, string, error) {
 
 	// 1. RawTransaction 구조체로 변환
 	var rawTransaction RawTransaction
 	err := json.Unmarshal([]byte(rawTransactionMessage), &rawTransaction)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse raw transaction: %v", err)
+		return "", "", newTxError(TxErrInvalid, fmt.Errorf("failed to parse raw transaction: %v", err))
 	}
 
 	// 2. 트랜잭션 필드 검증
-	err = ValidateTransactionFields(rawTransaction.From, rawTransaction.To, rawTransaction.Value.String(), rawTransaction.Signature, rawTransaction.Nonce)
+	err = ValidateTransactionFields(rawTransaction.From, rawTransaction.To, rawTransaction.Value.String(), rawTransaction.Signature, rawTransaction.Nonce, rawTransaction.Fee)
 	if err != nil {
-		return "", fmt.Errorf("transaction field validation failed: %v", err)
+		return "", "", newTxError(TxErrInvalid, fmt.Errorf("transaction field validation failed: %v", err))
 	}
 
 	// 3. 서명 검증
-	message := fmt.Sprintf("%s%s%s%d", rawTransaction.From, rawTransaction.To, rawTransaction.Value.String(), rawTransaction.Nonce)
-	messageHash := utils.Keccak256([]byte(message))
+	messageHash := TransactionSigningHash(rawTransaction.From, rawTransaction.To, rawTransaction.Value, rawTransaction.Nonce, rawTransaction.Fee)
 	decodedSignature, err := hex.DecodeString(rawTransaction.Signature)
 	if err != nil {
-		return "", fmt.Errorf("invalid signature format: %v", err)
+		return "", "", newTxError(TxErrBadSignature, fmt.Errorf("invalid signature format: %v", err))
 	}
 	isValidSig, err := VerifySignature(messageHash, decodedSignature, rawTransaction.From)
-	if err != nil || !isValidSig {
-		return "", fmt.Errorf("signature verification failed: %v", err)
+	if err != nil {
+		return "", "", newTxError(TxErrBadSignature, fmt.Errorf("signature verification failed: %v", err))
+	}
+	if !isValidSig {
+		return "", "", newTxError(TxErrBadSignature, fmt.Errorf("signature verification failed: signer is not %s", rawTransaction.From))
 	}
 
 	// 4. 계정 상태 확인
-	err = account.CheckAccountState(rawTransaction.From, rawTransaction.To, rawTransaction.Value.String(), rawTransaction.Nonce)
+	err = account.CheckAccountState(rawTransaction.From, rawTransaction.To, rawTransaction.Value.String(), feeOrZero(rawTransaction.Fee).String(), rawTransaction.Nonce)
 	if err != nil {
-		return "", fmt.Errorf("account state validation failed: %v", err)
+		return "", "", classifyTxError(fmt.Errorf("account state validation failed: %w", err))
 	}
 
 	// 5. 트랜잭션 생성
-	tx, jsonRawTransactionStr, err := CreateTransaction(rawTransaction.From, rawTransaction.To, rawTransaction.Signature, rawTransaction.Value, rawTransaction.Nonce)
+	tx, jsonRawTransactionStr, err := CreateTransaction(rawTransaction.From, rawTransaction.To, rawTransaction.Signature, rawTransaction.Value, rawTransaction.Nonce, rawTransaction.Fee)
 	if err != nil {
-		return "", fmt.Errorf("failed to create transaction: %v", err)
+		return "", "", newTxError(TxErrInternal, fmt.Errorf("failed to create transaction: %v", err))
 	}
 
 	// 6. Mempool에 저장
 	err = defaultMempool.AddTransaction(tx, rawTransaction.Nonce)
 	if err != nil {
-		return "", fmt.Errorf("failed to append transaction to mempool: %v", err)
+		return "", "", classifyTxError(fmt.Errorf("failed to append transaction to mempool: %w", err))
 	}
 
-	fmt.Printf("[TX] Validation completes, Hash : %v", tx.Hash)
+	fmt.Printf("[TX] Validation completes, Hash : %v\n", tx.Hash)
 
 	// 7. 반환
-	return jsonRawTransactionStr, nil
+	return tx.Hash, jsonRawTransactionStr, nil
 }
 
-// 트랜잭션 실행(db 상태 변경)
-func ExecuteTransactions(transactions []Transaction) error {
-	dbInstance, err := leveldb.GetDBInstance()
+// RPC로 받은 트랜잭션을 동기적으로 검증, 멤풀에 저장하고 해시 반환 (성공 시 피어에게 전파)
+func SubmitTransaction(rawTransactionMessage string) (string, error) {
+	txHash, processedMessage, err := ProcessTransaction(rawTransactionMessage)
 	if err != nil {
-		return fmt.Errorf("failed to get dbinstance : %v", err)
+		fmt.Printf("[TX] Validation failed : %v\n", err)
+		return "", err
 	}
 
-	for _, tx := range transactions {
-
-		batch := new(db.Batch)
-
-		// 1. From 가져오기
-		fromAccount, err := account.GetAccount(tx.From)
-		if err != nil {
-			return fmt.Errorf("failed to retrieve from account : %s : %v", tx.From, err)
-		}
-
-		// 2. To 가져오기 (없는 경우 생성)
-		toAccount, err := account.GetAccount(tx.To)
-		if err != nil {
-			if err == db.ErrNotFound { // 없는 경우 생성
-				_, createErr := account.StoreAccount(tx.To)
-				if createErr != nil {
-					return fmt.Errorf("failed to create to account %s: %v", tx.To, err)
-				}
-				toAccount, _ = account.GetAccount(tx.To)
-			} else {
-				return fmt.Errorf("failed to retrieve to account %s:%v", tx.To, err)
-			}
-		}
-
-		// 3. 잔액 검증 및 트랜잭션 실행
-		txValue := tx.Value
-		if fromAccount.Balance.Cmp(txValue) < 0 {
-			return fmt.Errorf("insufficient balance in from account %s", tx.From)
-		}
-
-		fromAccount.Balance.Sub(fromAccount.Balance, txValue)
-		toAccount.Balance.Add(toAccount.Balance, txValue)
-		fromAccount.Nonce++
-
-		// 4. 계정 데이터 저장 (배치 작업 추가)
-		fromAccountKey := append([]byte("account:"), []byte(tx.From)...)
-		toAccountKey := append([]byte("account:"), []byte(tx.To)...)
-
-		fromAccountJSON, err := json.Marshal(fromAccount)
-		if err != nil {
-			return fmt.Errorf("failed to serialize from account: %v", err)
-		}
-
-		toAccountJSON, err := json.Marshal(toAccount)
-		if err != nil {
-			return fmt.Errorf("failed to serialize to account: %v", err)
-		}
-
-		batch.Put(fromAccountKey, fromAccountJSON)
-		batch.Put(toAccountKey, toAccountJSON)
-
-		// 5. 배치 실행
-		err = dbInstance.Write(batch, nil)
-		if err != nil {
-			return fmt.Errorf("failed to execute batch write : %v", err)
-		}
-
-		fmt.Printf("[TX] Execution completed, Hash : %s, From : %s, To: %s, Value : %s\n", tx.Hash, tx.From, tx.To, tx.Value)
-
-	}
-
-	return nil
-}
-
-// 블록 내 트랜잭션들 검증
-func ProcessTransactionFromBlock(tx Transaction) (string, error) {
-	// 1. 트랜잭션 필드 검증
-	err := ValidateTransactionFields(tx.From, tx.To, tx.Value.String(), tx.Signature, tx.Nonce)
-	if err != nil {
-		return "", fmt.Errorf("transaction field validation failed : %v", err)
-	}
-
-	// 2. 서명 검증
-	txMessage := fmt.Sprintf("%s%s%s%d", tx.From, tx.To, tx.Value.String(), tx.Nonce)
-	txMessageHash := utils.Keccak256([]byte(txMessage))
-	decodedSignature, err := hex.DecodeString(tx.Signature)
-	if err != nil {
-		return "", fmt.Errorf("invalid transaction signature format : %v", err)
-	}
-	isValidSig, err := VerifySignature(txMessageHash, decodedSignature, tx.From)
-	if err != nil || !isValidSig {
-		return "", fmt.Errorf("transaction signature validation failed : %v", err)
-	}
-
-	// 3. 계정 상태 확인
-	err = account.CheckAccountState(tx.From, tx.To, tx.Value.String(), tx.Nonce)
-	if err != nil {
-		return "", fmt.Errorf("transaction state validation failed : %v", err)
-	}
+	// 재시작 후에도 남도록 저널에 기록
+	journalTransaction(txHash, processedMessage)
 
-	fmt.Printf("[TX] Validation compeleted, hash : %v\n", tx.Hash)
+	// 검증 결과 발행 (p2p가 피어에게 전파)
+	mediator.GetMediatorInstance().Publish(mediator.TxValidationResult{
+		Source:  mediator.SourceRPC,
+		TxHash:  txHash,
+		Payload: processedMessage,
+	})
 
-	// 4. 성공적으로 검증된 트랜잭션 반환
-	return tx.Hash, nil
+	return txHash, nil
 }
@@ -0,0 +1,65 @@
+package blockchain
+
+import (
+	"errors"
+	"simple_p2p_client/account"
+)
+
+// 멤풀에 같은 from, nonce의 트랜잭션이 이미 있음
+var ErrDuplicateTransaction = errors.New("duplicate transaction")
+
+// 같은 nonce의 트랜잭션을 교체하기에 수수료 인상폭이 부족함
+var ErrReplaceUnderpriced = errors.New("replacement transaction underpriced")
+
+// 멤풀이 가득 참
+var ErrPoolFull = errors.New("transaction pool is full")
+
+// 트랜잭션 제출 실패 원인 코드 (RPC 에러 코드로 그대로 사용)
+type TxErrorCode int
+
+const (
+	TxErrInvalid           TxErrorCode = 1000 // 파싱 실패, 필드 오류
+	TxErrBadSignature      TxErrorCode = 1001 // 서명 형식 오류, 서명자 != from
+	TxErrNonceTooLow       TxErrorCode = 1002 // 이미 사용된 nonce
+	TxErrInsufficientFunds TxErrorCode = 1003 // 잔액 부족
+	TxErrDuplicate         TxErrorCode = 1004 // 멤풀에 이미 있는 트랜잭션
+	TxErrPoolFull          TxErrorCode = 1005 // 멤풀 용량 초과
+	TxErrUnknownAccount    TxErrorCode = 1006 // from 계정이 없음
+	TxErrUnderpriced       TxErrorCode = 1007 // 교체 트랜잭션의 수수료 인상폭 부족
+	TxErrInternal          TxErrorCode = 1099 // DB 오류 등 노드 내부 오류
+)
+
+// 코드가 붙은 트랜잭션 제출 에러
+type TxError struct {
+	Code    TxErrorCode
+	Message string
+}
+
+func (e *TxError) Error() string {
+	return e.Message
+}
+
+// 코드와 원인 에러로 TxError 생성
+func newTxError(code TxErrorCode, err error) *TxError {
+	return &TxError{Code: code, Message: err.Error()}
+}
+
+// 계정 상태, 멤풀 에러를 원인에 맞는 코드로 분류
+func classifyTxError(err error) *TxError {
+	switch {
+	case errors.Is(err, account.ErrNonceTooLow):
+		return newTxError(TxErrNonceTooLow, err)
+	case errors.Is(err, account.ErrInsufficientFunds):
+		return newTxError(TxErrInsufficientFunds, err)
+	case errors.Is(err, account.ErrUnknownAccount):
+		return newTxError(TxErrUnknownAccount, err)
+	case errors.Is(err, ErrDuplicateTransaction):
+		return newTxError(TxErrDuplicate, err)
+	case errors.Is(err, ErrReplaceUnderpriced):
+		return newTxError(TxErrUnderpriced, err)
+	case errors.Is(err, ErrPoolFull):
+		return newTxError(TxErrPoolFull, err)
+	default:
+		return newTxError(TxErrInternal, err)
+	}
+}
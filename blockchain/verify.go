@@ -0,0 +1,298 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"simple_p2p_client/account"
+	"simple_p2p_client/leveldb"
+
+	db "github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// 체인 무결성 검증에서 처음 발견한 불일치
+type Divergence struct {
+	Block   uint64 // 불일치가 발견된 블록 번호 (상태 비교 단계면 0)
+	Hash    string // 블록 해시
+	Account string // 상태 비교 단계에서 불일치한 계정
+	Reason  string
+}
+
+func (d *Divergence) Error() string {
+	if d.Account != "" {
+		return fmt.Sprintf("state divergence at account %s : %s", d.Account, d.Reason)
+	}
+	if d.Block == 0 {
+		return fmt.Sprintf("divergence : %s", d.Reason)
+	}
+	return fmt.Sprintf("divergence at block %d (%s) : %s", d.Block, d.Hash, d.Reason)
+}
+
+// 체인 무결성 검증 결과
+type VerifyResult struct {
+	Blocks       uint64   // 확인한 블록 수
+	Transactions int      // 다시 실행한 트랜잭션 수
+	Accounts     int      // 비교한 계정 수
+	Supply       *big.Int // 다시 계산한 총 발행량
+}
+
+// 제네시스부터 lastblock까지 부모 해시를 따라가며 블록을 다시 검증하고,
+// 제네시스 할당부터 모든 트랜잭션을 빈 상태에 다시 실행해 DB의 account: 항목, 총 발행량과 비교
+// 불일치는 *Divergence 에러로 반환 (첫 번째 불일치만), progress는 블록마다 호출
+func VerifyChain(progress func(number uint64) error) (*VerifyResult, error) {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dbinstance : %v", err)
+	}
+
+	// 체인을 만들 때의 제네시스 설정 (보상, 소각 규칙), 실행 중인 노드의 설정은 바꾸지 않음
+	config, err := loadStoredGenesisConfig(dbInstance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load genesis config : %v", err)
+	}
+
+	// 스냅샷으로 복원한 체인은 스냅샷 이전 블록이 없어 제네시스부터 다시 실행할 수 없음
+	base, err := getSnapshotBase()
+	if err != nil {
+		return nil, err
+	}
+	if base != nil {
+		return nil, fmt.Errorf("chain was restored from a snapshot at block %d, blocks before it are not available", base.Number)
+	}
+	tail, err := pruneTail(dbInstance)
+	if err != nil {
+		return nil, err
+	}
+	if tail > 1 {
+		return nil, fmt.Errorf("transactions of blocks before %d have been pruned, verify requires an archive node", tail)
+	}
+
+	// 1. lastblock부터 부모 해시를 따라 제네시스까지
+	hashes, err := canonicalHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 제네시스부터 블록 검증, 빈 상태에 다시 실행
+	scratch := &stateOverlay{
+		accounts: make(map[string]*account.Account),
+		load: func(address string) (*account.Account, error) {
+			return &account.Account{Balance: big.NewInt(0), Nonce: 0}, nil
+		},
+		reward: config.Reward,
+	}
+	result := &VerifyResult{Supply: big.NewInt(0)}
+
+	var parent *Block
+	for _, hash := range hashes {
+		block, err := GetBlockByHash(hash)
+		if err != nil {
+			return result, err
+		}
+		if err := verifyStoredBlock(config, block, parent, scratch, result); err != nil {
+			return result, &Divergence{Block: block.Number, Hash: block.Hash, Reason: err.Error()}
+		}
+		result.Blocks++
+		parent = block
+
+		if progress != nil {
+			if err := progress(block.Number); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	// 3. 다시 계산한 상태와 DB 상태 비교
+	if err := compareAccounts(dbInstance, scratch, result); err != nil {
+		return result, err
+	}
+
+	// 총 발행량 (기록이 없는 예전 체인이면 건너뜀, 검증은 DB를 변경하지 않음)
+	supplyData, err := dbInstance.Get([]byte(totalSupplyKey), nil)
+	if errors.Is(err, db.ErrNotFound) {
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+	if string(supplyData) != result.Supply.String() {
+		return result, &Divergence{Reason: fmt.Sprintf("total supply is %s, expected %s", supplyData, result.Supply)}
+	}
+	return result, nil
+}
+
+// lastblock부터 부모 해시를 따라가며 모은 블록 해시 (제네시스부터 순서대로)
+func canonicalHashes() ([]string, error) {
+	head, err := GetLatestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lastblock : %v", err)
+	}
+
+	hashes := make([]string, 0, head.Number)
+	block := head
+	for {
+		hashes = append(hashes, block.Hash)
+		if block.Number <= 1 {
+			break
+		}
+		parent, err := GetBlockByHash(block.ParentHash)
+		if err != nil {
+			return nil, &Divergence{Block: block.Number, Hash: block.Hash, Reason: fmt.Sprintf("parent block is missing : %v", err)}
+		}
+		if parent.Number+1 != block.Number {
+			return nil, &Divergence{Block: block.Number, Hash: block.Hash, Reason: fmt.Sprintf("parent block number is %d", parent.Number)}
+		}
+		block = parent
+	}
+	if block.Number != 1 || block.ParentHash != "0x0" {
+		return nil, &Divergence{Block: block.Number, Hash: block.Hash, Reason: "chain does not start at the genesis block"}
+	}
+
+	for i, j := 0, len(hashes)-1; i < j; i, j = i+1, j-1 {
+		hashes[i], hashes[j] = hashes[j], hashes[i]
+	}
+	return hashes, nil
+}
+
+// 저장된 블록 하나를 부모 기준으로 검증하고 빈 상태(scratch)에 실행 (config : 체인의 제네시스 설정)
+func verifyStoredBlock(config GenesisConfig, block, parent *Block, scratch *stateOverlay, result *VerifyResult) error {
+	if err := verifyBlockHash(block); err != nil {
+		return err
+	}
+
+	// 번호 인덱스 (인덱스가 없는 예전 블록은 건너뜀)
+	if err := verifyBlockIndexes(block); err != nil {
+		return err
+	}
+
+	// 제네시스 : 설정의 miner에게 초기 발행량 할당
+	if parent == nil {
+		if err := config.checkGenesisBlock(block); err != nil {
+			return err
+		}
+		if block.MerkleRoot != "0x0" || len(block.Transaction) != 0 {
+			return fmt.Errorf("genesis block must not contain transactions")
+		}
+		miner, _ := scratch.get(block.Miner)
+		miner.Balance.Add(miner.Balance, config.Balance)
+		result.Supply.Add(result.Supply, config.Balance)
+		return nil
+	}
+
+	// 부모 해시, 번호, 타임스탬프 간격 (미래 시각 허용 범위는 받은 당시 기준이므로 블록 시각이 현재보다 늦어도 통과)
+	now := clock.Now()
+	if blockTime := time.Unix(int64(block.Timestamp), 0); blockTime.After(now) {
+		now = blockTime
+	}
+	if err := validateBlockHeader(block, parent, now); err != nil {
+		return err
+	}
+	if err := verifyMerkleRoot(block); err != nil {
+		return err
+	}
+	if err := config.Reward.validateCoinbase(block); err != nil {
+		return err
+	}
+
+	// 트랜잭션 해시, 서명, nonce, 잔액 검증과 실행
+	if err := validateBlockTransactions(block, scratch); err != nil {
+		return err
+	}
+	result.Transactions += len(block.Transaction)
+
+	// 블록 보상 (트랜잭션 실행 후, commitBlock과 같은 순서)
+	miner, _ := scratch.get(block.Miner)
+	miner.Balance.Add(miner.Balance, block.Coinbase.Reward)
+	result.Supply.Add(result.Supply, block.Coinbase.Reward)
+	result.Supply.Sub(result.Supply, block.Coinbase.Burned)
+	return nil
+}
+
+// 블록 번호 인덱스, 트랜잭션 위치 인덱스가 블록을 가리키는지
+func verifyBlockIndexes(block *Block) error {
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return err
+	}
+
+	indexed, err := dbInstance.Get(blockNumberKey(block.Number), nil)
+	if err == nil && string(indexed) != block.Hash {
+		return fmt.Errorf("block number index points to %s", indexed)
+	}
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+
+	for i, tx := range block.Transaction {
+		lookupJSON, err := dbInstance.Get(txLookupKey(tx.Hash), nil)
+		if errors.Is(err, db.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		var lookup TxLookup
+		if err := json.Unmarshal(lookupJSON, &lookup); err != nil {
+			return fmt.Errorf("invalid tx lookup for %s : %v", tx.Hash, err)
+		}
+		if lookup.BlockHash != block.Hash || lookup.Index != i {
+			return fmt.Errorf("tx lookup for %s points to block %s index %d, expected index %d", tx.Hash, lookup.BlockHash, lookup.Index, i)
+		}
+	}
+	return nil
+}
+
+// DB의 모든 account: 항목과 다시 계산한 상태 비교 (주소 순서로 첫 불일치 반환)
+// 트랜잭션 제출 시 만들어진 빈 계정은 다시 계산한 상태에 없어도 잔액 0, nonce 0이면 일치로 봄
+func compareAccounts(dbInstance *db.DB, scratch *stateOverlay, result *VerifyResult) error {
+	stored := make(map[string]*account.Account)
+	iter := dbInstance.NewIterator(util.BytesPrefix([]byte("account:")), nil)
+	for iter.Next() {
+		var accountData account.Account
+		if err := json.Unmarshal(iter.Value(), &accountData); err != nil {
+			iter.Release()
+			return &Divergence{Account: strings.TrimPrefix(string(iter.Key()), "account:"), Reason: fmt.Sprintf("invalid account entry : %v", err)}
+		}
+		if accountData.Balance == nil {
+			accountData.Balance = big.NewInt(0)
+		}
+		stored[strings.TrimPrefix(string(iter.Key()), "account:")] = &accountData
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	addresses := make([]string, 0, len(stored)+len(scratch.accounts))
+	for address := range stored {
+		addresses = append(addresses, address)
+	}
+	for address := range scratch.accounts {
+		if _, exists := stored[address]; !exists {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		expected, _ := scratch.get(address)
+		actual, exists := stored[address]
+		if !exists {
+			if expected.Balance.Sign() == 0 && expected.Nonce == 0 {
+				continue
+			}
+			return &Divergence{Account: address, Reason: fmt.Sprintf("missing account entry, expected balance %s, nonce %d", expected.Balance, expected.Nonce)}
+		}
+		if actual.Balance.Cmp(expected.Balance) != 0 || actual.Nonce != expected.Nonce {
+			return &Divergence{Account: address, Reason: fmt.Sprintf("stored balance %s, nonce %d, expected balance %s, nonce %d", actual.Balance, actual.Nonce, expected.Balance, expected.Nonce)}
+		}
+		result.Accounts++
+	}
+	return nil
+}
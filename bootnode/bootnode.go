@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"net"
+	"simple_p2p_client/p2p"
 	pc "simple_p2p_client/protocol_constants"
 	"strings"
 )
@@ -80,25 +81,27 @@ func tcpstartBootstrapServer(address string) {
 	}
 }
 
-func StartBootstrapServer() {
+// 부트스트랩 노드 실행 (listenAddress : host:port)
+func StartBootstrapServer(listenAddress string) {
 
 	// 연결된 노드들의 주소를 저장할 슬라이스
 	var connectedNodes []string
 
-	// create UDP address (localhost:8282)
-	addr := net.UDPAddr{
-		Port: 8282,
-		IP:   net.ParseIP("127.0.0.1"),
+	// create UDP address
+	addr, err := net.ResolveUDPAddr("udp", listenAddress)
+	if err != nil {
+		fmt.Println("Error resolving UDP address :", err)
+		return
 	}
 
 	// Waiting for UDP connection
-	conn, err := net.ListenUDP("udp", &addr)
+	conn, err := net.ListenUDP("udp", addr)
 	if err != nil {
 		fmt.Println("Error starting UDP server :", err)
 		return
 	}
 
-	fmt.Println("[BootNode] UDP server is listening on :::", fmt.Sprintf("%s:%d", addr.IP.String(), addr.Port))
+	fmt.Println("[BootNode] UDP server is listening on :::", addr.String())
 
 	defer conn.Close()
 
@@ -129,7 +132,8 @@ func StartBootstrapServer() {
 		case pc.NodeDiscoveryFindNode:
 			fmt.Printf("Received FindNode message from %s\n", remoteAddr.String())
 
-			nodeInfo := string(buf[1:n])
+			// 노드가 모든 인터페이스에서 리스닝하면 요청을 보낸 IP로 기록
+			nodeInfo := p2p.ResolveAdvertisedAddress(string(buf[1:n]), remoteAddr.IP)
 
 			connectedNodesString := strings.Join(connectedNodes, ",")
 			message := append([]byte{pc.NodeDiscoveryNeighbors}, []byte(connectedNodesString)...)
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"simple_p2p_client/account"
+	"simple_p2p_client/constants"
+	"simple_p2p_client/keystore"
+)
+
+const accountUsage = `Usage: simple_p2p_client account <command> [flags]
+
+Commands:
+  new      Create a new encrypted key in the keystore
+  list     List addresses in the keystore
+  import   Encrypt an existing hex private key into the keystore`
+
+// CLI 명령의 키 저장소 기본 경로 (nodeID가 default인 노드와 같은 경로)
+var defaultKeyStoreDir = filepath.Join(defaultDataDir, constants.KeyStoreDir)
+
+// account 서브커맨드 : 키 저장소 계정 관리
+func runAccountCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", accountUsage)
+	}
+
+	switch args[0] {
+	case "new":
+		return accountNew(args[1:])
+	case "list":
+		return accountList(args[1:])
+	case "import":
+		return accountImport(args[1:])
+	default:
+		return fmt.Errorf("unknown account command %q\n%s", args[0], accountUsage)
+	}
+}
+
+func accountNew(args []string) error {
+	fs := flag.NewFlagSet("account new", flag.ExitOnError)
+	keystoreDir := fs.String("keystore", defaultKeyStoreDir, "Keystore directory")
+	passwordFile := fs.String("passwordfile", "", "File containing the password used to encrypt the key")
+	fs.Parse(args)
+
+	password, err := readPasswordFile(*passwordFile)
+	if err != nil {
+		return err
+	}
+	ks, err := keystore.NewKeyStore(*keystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return err
+	}
+
+	address, err := ks.NewAccount(password)
+	if err != nil {
+		return err
+	}
+	fmt.Println(address)
+	return nil
+}
+
+func accountList(args []string) error {
+	fs := flag.NewFlagSet("account list", flag.ExitOnError)
+	keystoreDir := fs.String("keystore", defaultKeyStoreDir, "Keystore directory")
+	fs.Parse(args)
+
+	ks, err := keystore.NewKeyStore(*keystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return err
+	}
+	addresses, err := ks.Accounts()
+	if err != nil {
+		return err
+	}
+	for i, address := range addresses {
+		fmt.Printf("#%d  %s\n", i, address)
+	}
+	return nil
+}
+
+func accountImport(args []string) error {
+	fs := flag.NewFlagSet("account import", flag.ExitOnError)
+	keystoreDir := fs.String("keystore", defaultKeyStoreDir, "Keystore directory")
+	passwordFile := fs.String("passwordfile", "", "File containing the password used to encrypt the key")
+	keyFile := fs.String("keyfile", "", "File containing the hex private key (required)")
+	fs.Parse(args)
+
+	if *keyFile == "" {
+		return fmt.Errorf("-keyfile is required")
+	}
+	data, err := os.ReadFile(*keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read key file : %v", err)
+	}
+	privateKey, err := account.LoadPrivateKey(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return err
+	}
+
+	password, err := readPasswordFile(*passwordFile)
+	if err != nil {
+		return err
+	}
+	ks, err := keystore.NewKeyStore(*keystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return err
+	}
+
+	address, err := ks.ImportECDSA(privateKey, password)
+	if err != nil {
+		return err
+	}
+	fmt.Println(address)
+	return nil
+}
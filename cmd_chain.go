@@ -0,0 +1,329 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"simple_p2p_client/blockchain"
+	"simple_p2p_client/constants"
+	"simple_p2p_client/leveldb"
+)
+
+// 진행 상황 출력 주기
+const progressInterval = 5 * time.Second
+
+// 진행 상황을 주기적으로 출력하고, Ctrl+C를 받으면 블록 사이에서 중단하는 콜백
+// 반환된 함수로 시그널 구독 해제
+func chainFileProgress(action string) (blockchain.ChainFileProgressFunc, func()) {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	start := time.Now()
+	lastReport := start
+	progress := func(status blockchain.ChainFileProgress) error {
+		select {
+		case <-interrupt:
+			return fmt.Errorf("interrupted at block %d", status.Number)
+		default:
+		}
+		if time.Since(lastReport) >= progressInterval {
+			lastReport = time.Now()
+			elapsed := time.Since(start).Seconds()
+			fmt.Printf("%s block %d : %d blocks, %d skipped, %.1f blocks/s\n",
+				action, status.Number, status.Processed, status.Skipped, float64(status.Processed)/elapsed)
+		}
+		return nil
+	}
+	return progress, func() { signal.Stop(interrupt) }
+}
+
+// export : 체인을 파일로 내보내기
+func runExportCommand(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	dataDir := dataDirFlag(fs)
+	first := fs.Uint64("first", 1, "First block number to export")
+	last := fs.Uint64("last", 0, "Last block number to export (0 for the chain head)")
+	compress := fs.Bool("gzip", false, "Compress the file with gzip (default when the file name ends with .gz)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("Usage: simple_p2p_client export [-datadir dir] [-first n] [-last n] [-gzip] <file>")
+	}
+	path := fs.Arg(0)
+
+	closeDB, err := openDatabase(*dataDir)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create export file : %v", err)
+	}
+	defer file.Close()
+
+	progress, stop := chainFileProgress("Exporting")
+	defer stop()
+
+	start := time.Now()
+	exported, err := blockchain.ExportChain(file, *first, *last, *compress || strings.HasSuffix(path, ".gz"), progress)
+	if err != nil {
+		return fmt.Errorf("export stopped after %d blocks : %v", exported, err)
+	}
+	fmt.Printf("Exported %d blocks to %s in %s\n", exported, path, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// import : 체인 파일의 블록을 검증, 실행해 가져오기 (이미 있는 블록은 건너뛰므로 중단 후 다시 실행하면 이어서 가져옴)
+func runImportCommand(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dataDir := dataDirFlag(fs)
+	genesisFile := fs.String("genesis", "", "Genesis config JSON file (used only when the data directory is empty)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("Usage: simple_p2p_client import [-datadir dir] [-genesis file] <file>")
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to open chain file : %v", err)
+	}
+	defer file.Close()
+
+	closeDB, err := openDatabase(*dataDir)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	blockchain.SetGenesisFile(*genesisFile)
+	if err := blockchain.InitGenesisBlock(); err != nil {
+		return err
+	}
+
+	progress, stop := chainFileProgress("Importing")
+	defer stop()
+
+	start := time.Now()
+	status, err := blockchain.ImportChain(file, progress)
+	if err != nil {
+		return fmt.Errorf("import stopped after %d blocks (%d skipped) : %v", status.Processed, status.Skipped, err)
+	}
+	fmt.Printf("Imported %d blocks from %s in %s, %d already present\n", status.Processed, fs.Arg(0), time.Since(start).Round(time.Millisecond), status.Skipped)
+	return nil
+}
+
+// verify : 체인 무결성 검증 (블록 해시, 머클루트, 서명을 다시 확인하고 상태를 다시 계산해 DB와 비교)
+func runVerifyCommand(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	dataDir := dataDirFlag(fs)
+	fs.Parse(args)
+
+	closeDB, err := openDatabase(*dataDir)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	head, err := blockchain.GetLatestBlock()
+	if err != nil {
+		return fmt.Errorf("data directory %s is not initialized : %v", *dataDir, err)
+	}
+
+	start := time.Now()
+	lastReport := start
+	result, err := blockchain.VerifyChain(func(number uint64) error {
+		if time.Since(lastReport) >= progressInterval {
+			lastReport = time.Now()
+			fmt.Printf("Verifying block %d / %d\n", number, head.Number)
+		}
+		return nil
+	})
+	if err != nil {
+		var divergence *blockchain.Divergence
+		if errors.As(err, &divergence) && result != nil {
+			fmt.Printf("Verified %d blocks before the first divergence\n", result.Blocks)
+		}
+		return err
+	}
+
+	fmt.Printf("Chain is consistent : %d blocks, %d transactions, %d accounts, total supply %s (%s)\n",
+		result.Blocks, result.Transactions, result.Accounts, result.Supply, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+const dbUsage = `Usage: simple_p2p_client db <command> [flags]
+
+Commands:
+  inspect   Print the chain head and key counts of the database
+  stats     Print the database size, table levels and retained block ranges
+  compact   Compact the whole database to reclaim space of deleted data`
+
+// db : 데이터베이스 도구
+func runDBCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", dbUsage)
+	}
+
+	switch args[0] {
+	case "inspect":
+		return dbInspect(args[1:])
+	case "stats":
+		return dbStats(args[1:])
+	case "compact":
+		return dbCompact(args[1:])
+	default:
+		return fmt.Errorf("unknown db command %q\n%s", args[0], dbUsage)
+	}
+}
+
+func dbInspect(args []string) error {
+	fs := flag.NewFlagSet("db inspect", flag.ExitOnError)
+	dataDir := dataDirFlag(fs)
+	fs.Parse(args)
+
+	closeDB, err := openDatabase(*dataDir)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	dbInstance, err := leveldb.GetDBInstance()
+	if err != nil {
+		return err
+	}
+
+	// 키 종류별 개수, 크기
+	type keyStats struct {
+		count int
+		size  int
+	}
+	stats := make(map[string]*keyStats)
+	iter := dbInstance.NewIterator(nil, nil)
+	for iter.Next() {
+		category := keyCategory(string(iter.Key()))
+		if stats[category] == nil {
+			stats[category] = &keyStats{}
+		}
+		stats[category].count++
+		stats[category].size += len(iter.Key()) + len(iter.Value())
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Data directory : %s\n", *dataDir)
+	if head, err := blockchain.GetLatestBlock(); err == nil {
+		fmt.Printf("Head block     : %d (%s)\n", head.Number, head.Hash)
+	} else {
+		fmt.Println("Head block     : none (not initialized)")
+	}
+	if supply, err := blockchain.GetTotalSupply(); err == nil {
+		fmt.Printf("Total supply   : %s\n", supply)
+	}
+
+	categories := make([]string, 0, len(stats))
+	for category := range stats {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	fmt.Printf("\n%-16s %10s %12s\n", "Category", "Keys", "Bytes")
+	for _, category := range categories {
+		fmt.Printf("%-16s %10d %12d\n", category, stats[category].count, stats[category].size)
+	}
+	return nil
+}
+
+func dbStats(args []string) error {
+	fs := flag.NewFlagSet("db stats", flag.ExitOnError)
+	dataDir := dataDirFlag(fs)
+	fs.Parse(args)
+
+	closeDB, err := openDatabase(*dataDir)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	stats, err := leveldb.GetStats()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Path           : %s\n", stats.Path)
+	fmt.Printf("Disk size      : %d bytes\n", stats.DiskSize)
+	if head, err := blockchain.GetLatestBlock(); err == nil {
+		fmt.Printf("Head block     : %d\n", head.Number)
+		if tail, err := blockchain.GetPruneTail(); err == nil {
+			fmt.Printf("Oldest body    : %d\n", tail)
+		}
+		if oldest, err := blockchain.GetOldestStateBlock(); err == nil {
+			fmt.Printf("Oldest state   : %d\n", oldest)
+		}
+	}
+
+	fmt.Printf("\n%-6s %8s %12s\n", "Level", "Tables", "Bytes")
+	for _, level := range stats.Levels {
+		fmt.Printf("%-6d %8d %12d\n", level.Level, level.Tables, level.Size)
+	}
+	return nil
+}
+
+// 노드가 실행 중이면 DB가 잠겨 있으므로 RPC db.Compact 사용
+func dbCompact(args []string) error {
+	fs := flag.NewFlagSet("db compact", flag.ExitOnError)
+	dataDir := dataDirFlag(fs)
+	fs.Parse(args)
+
+	closeDB, err := openDatabase(*dataDir)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	start := time.Now()
+	before, after, err := leveldb.Compact()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Compacted %s in %s : %d -> %d bytes\n", *dataDir, time.Since(start).Round(time.Millisecond), before, after)
+	return nil
+}
+
+// DB 키 종류 (block.go, genesis.go의 키 형식)
+func keyCategory(key string) string {
+	switch {
+	case strings.HasPrefix(key, "account:"):
+		return "accounts"
+	case strings.HasPrefix(key, "block:"):
+		return "block numbers"
+	case strings.HasPrefix(key, "tx:"):
+		return "tx lookups"
+	case strings.HasPrefix(key, "history:"), strings.HasPrefix(key, "changes:"):
+		return "state history"
+	case strings.HasPrefix(key, "addrtx:"):
+		return "address index"
+	case len(key) == 64:
+		return "blocks"
+	default:
+		return "metadata"
+	}
+}
+
+// version : 버전 정보
+func runVersionCommand(args []string) error {
+	fmt.Printf("%s %s\n", constants.ClientName, constants.ClientVersion)
+	fmt.Printf("Chain ID : %d\n", constants.ChainID)
+	fmt.Printf("Go       : %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	return nil
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"simple_p2p_client/blockchain"
+	"simple_p2p_client/bootnode"
+	"simple_p2p_client/config"
+	"simple_p2p_client/constants"
+	"simple_p2p_client/keystore"
+	"simple_p2p_client/leveldb"
+	"simple_p2p_client/p2p"
+	rpcserver "simple_p2p_client/rpc-server"
+	"simple_p2p_client/utils"
+)
+
+// 데이터 디렉토리 기본값 (DB, 키 저장소, 멤풀 저널)
+const defaultDataDir = "./db/default"
+
+// -datadir 플래그 등록
+func dataDirFlag(fs *flag.FlagSet) *string {
+	return fs.String("datadir", defaultDataDir, "Data directory for the database, keystore and mempool journal")
+}
+
+// 데이터 디렉토리의 DB 열기, 반환된 함수로 닫기
+func openDatabase(dataDir string) (func(), error) {
+	leveldb.SetDBPath(dataDir)
+	if err := leveldb.InitDB(); err != nil {
+		return nil, fmt.Errorf("failed to initialize DB at %s : %v", dataDir, err)
+	}
+	return func() {
+		if err := leveldb.CleanupDB(); err != nil {
+			fmt.Printf("Failed to cleanup DB: %v\n", err)
+		}
+	}, nil
+}
+
+// init : 제네시스 설정 파일로 데이터 디렉토리 초기화
+func runInitCommand(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	dataDir := dataDirFlag(fs)
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("Usage: simple_p2p_client init [-datadir dir] <genesis.json>")
+	}
+	if _, err := blockchain.LoadGenesisFile(fs.Arg(0)); err != nil {
+		return err
+	}
+
+	closeDB, err := openDatabase(*dataDir)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	if _, err := blockchain.GetLatestBlock(); err == nil {
+		return fmt.Errorf("data directory %s is already initialized", *dataDir)
+	}
+	blockchain.SetGenesisFile(fs.Arg(0))
+	return blockchain.InitGenesisBlock()
+}
+
+// run, dumpconfig 공통 플래그 (설정 파일, 환경 변수보다 우선)
+type nodeFlags struct {
+	configFile   *string
+	dataDir      *string
+	nodeID       *string
+	port         *int
+	listenAddr   *string
+	bootnodes    *string
+	nat          *string
+	maxPeers     *int
+	rpcHost      *string
+	rpcPort      *int
+	rpcAPI       *string
+	noRPC        *bool
+	mine         *bool
+	genesisFile  *string
+	keystoreDir  *string
+	passwordFile *string
+	priceBump    *uint64
+	snapInterval *uint64
+	historyWin   *uint64
+	prune        *uint64
+	compaction   *time.Duration
+	mode         *string
+}
+
+func newNodeFlagSet(name string) (*flag.FlagSet, *nodeFlags) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	f := &nodeFlags{
+		configFile:   fs.String("config", "", "TOML config file (flags and SBC_<SECTION>_<KEY> environment variables override it)"),
+		dataDir:      fs.String("datadir", "", "Data directory for the database, keystore and mempool journal (default ./db/<nodeID>)"),
+		nodeID:       fs.String("nodeID", "default", "Node identifier, selects ./db/<nodeID> when -datadir is not set"),
+		port:         fs.Int("port", 30303, "The port on which the server listen (TCP & UDP), keeps the host of p2p.listen_addr"),
+		listenAddr:   fs.String("listenaddr", "", "host:port on which the server listen (TCP & UDP)"),
+		bootnodes:    fs.String("bootnodes", "", "Comma separated bootnode addresses, tried in order"),
+		nat:          fs.String("nat", "none", "Address advertised to other nodes : none (listen address) or extip:<ip>"),
+		maxPeers:     fs.Int("maxpeers", constants.DefaultMaxPeers, "Maximum number of connected peers (0 for no limit)"),
+		rpcHost:      fs.String("rpchost", "", "Interface on which the RPC server listens (all interfaces if empty)"),
+		rpcPort:      fs.Int("rpcport", 8080, "The port on which the RPC server listens"),
+		rpcAPI:       fs.String("rpcapi", "", "Comma separated RPC namespaces to enable (default "+strings.Join(config.DefaultNamespaces, ",")+", available : "+strings.Join(config.AllNamespaces, ",")+")"),
+		noRPC:        fs.Bool("norpc", false, "Disable the RPC server"),
+		mine:         fs.Bool("mine", true, "Create blocks from the mempool"),
+		genesisFile:  fs.String("genesis", "", "Genesis config JSON file (used only when the chain is first created)"),
+		keystoreDir:  fs.String("keystore", "", "Directory of encrypted key files (default <datadir>/keystore)"),
+		passwordFile: fs.String("passwordfile", "", "File containing the node account password (empty password if not set, for testing only)"),
+		priceBump:    fs.Uint64("pricebump", constants.DefaultPriceBump, "Minimum fee bump (%) to replace a pending transaction with the same nonce"),
+		snapInterval: fs.Uint64("snapshotinterval", 0, "Save a state snapshot to <datadir>/snapshots every N blocks (0 to disable)"),
+		historyWin:   fs.Uint64("historywindow", 0, "Number of recent blocks whose account state can be queried (0 for an archive node keeping all)"),
+		prune:        fs.Uint64("prune", 0, "Keep transactions of only the most recent N blocks, headers are always kept (0 to keep all)"),
+		compaction:   fs.Duration("compactioninterval", 0, "Interval of full database compactions (0 to disable)"),
+		mode:         fs.String("mode", "fullnode", "Deprecated : use the bootnode command instead of -mode=bootnode"),
+	}
+	return fs, f
+}
+
+// 기본값 < 설정 파일 < 환경 변수 < 명시적으로 지정한 플래그 순으로 최종 설정 생성
+func (f *nodeFlags) load(fs *flag.FlagSet) (*config.Config, error) {
+	cfg, err := config.Load(*f.configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(fl *flag.Flag) { set[fl.Name] = true })
+
+	switch {
+	case set["datadir"]:
+		cfg.Node.DataDir = *f.dataDir
+	case set["nodeID"]:
+		cfg.Node.DataDir = fmt.Sprintf("./db/%s", *f.nodeID)
+	}
+	if set["keystore"] {
+		cfg.Node.Keystore = *f.keystoreDir
+	}
+	if set["passwordfile"] {
+		cfg.Node.PasswordFile = *f.passwordFile
+	}
+	if set["genesis"] {
+		cfg.Node.Genesis = *f.genesisFile
+	}
+	if set["listenaddr"] {
+		cfg.P2P.ListenAddr = *f.listenAddr
+	}
+	if set["port"] {
+		host, _, err := net.SplitHostPort(cfg.P2P.ListenAddr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid p2p listen address %q : %v", cfg.P2P.ListenAddr, err)
+		}
+		cfg.P2P.ListenAddr = net.JoinHostPort(host, strconv.Itoa(*f.port))
+	}
+	if set["bootnodes"] {
+		cfg.P2P.Bootnodes = splitList(*f.bootnodes)
+	}
+	if set["nat"] {
+		cfg.P2P.NAT = *f.nat
+	}
+	if set["maxpeers"] {
+		cfg.P2P.MaxPeers = *f.maxPeers
+	}
+	if set["rpchost"] {
+		cfg.RPC.Host = *f.rpcHost
+	}
+	if set["rpcport"] {
+		cfg.RPC.Port = *f.rpcPort
+	}
+	if set["rpcapi"] {
+		cfg.RPC.Namespaces = splitList(*f.rpcAPI)
+	}
+	if set["norpc"] {
+		cfg.RPC.Enabled = !*f.noRPC
+	}
+	if set["mine"] {
+		cfg.Mining.Enabled = *f.mine
+	}
+	if set["pricebump"] {
+		cfg.Mempool.PriceBump = *f.priceBump
+	}
+	if set["snapshotinterval"] {
+		cfg.Snapshot.Interval = *f.snapInterval
+	}
+	if set["historywindow"] {
+		cfg.State.HistoryWindow = *f.historyWin
+	}
+	if set["prune"] {
+		cfg.State.PruneBlocks = *f.prune
+	}
+	if set["compactioninterval"] {
+		cfg.Database.CompactionInterval = *f.compaction
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration : %v", err)
+	}
+	return cfg, nil
+}
+
+// 쉼표로 구분된 목록
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// run : 풀노드 실행
+func runNodeCommand(args []string) error {
+	fs, f := newNodeFlagSet("run")
+	fs.Parse(args)
+
+	switch *f.mode {
+	case "fullnode":
+	case "bootnode":
+		return runBootnodeCommand(nil)
+	default:
+		return fmt.Errorf("invalid mode %q, use the run or bootnode command", *f.mode)
+	}
+
+	cfg, err := f.load(fs)
+	if err != nil {
+		return err
+	}
+
+	keystoreDir := cfg.Node.Keystore
+	if keystoreDir == "" {
+		keystoreDir = filepath.Join(cfg.Node.DataDir, constants.KeyStoreDir)
+	}
+
+	blockchain.SetJournalPath(filepath.Join(cfg.Node.DataDir, constants.MempoolJournalFile))
+	blockchain.SetJournalRotation(cfg.Mempool.JournalRotation)
+	blockchain.SetPriceBump(cfg.Mempool.PriceBump)
+	blockchain.SetMempoolLimits(blockchain.MempoolLimits{
+		GlobalPendingSlots:  cfg.Mempool.GlobalPendingSlots,
+		GlobalFutureSlots:   cfg.Mempool.GlobalFutureSlots,
+		AccountPendingSlots: cfg.Mempool.AccountPendingSlots,
+		AccountFutureSlots:  cfg.Mempool.AccountFutureSlots,
+		FutureLifetime:      cfg.Mempool.FutureLifetime,
+	})
+	blockchain.SetBlockCreation(cfg.Mining.BlockInterval, cfg.Mining.TxsPerBlock)
+	blockchain.SetGenesisFile(cfg.Node.Genesis)
+	blockchain.SetStateHistoryWindow(cfg.State.EffectiveHistoryWindow())
+	blockchain.SetPruneBlocks(cfg.State.PruneBlocks)
+	p2p.SetMaxPeers(cfg.P2P.MaxPeers)
+	externalIP, err := p2p.ParseNAT(cfg.P2P.NAT)
+	if err != nil {
+		return err
+	}
+	p2p.SetExternalIP(externalIP)
+
+	closeDB, err := openDatabase(cfg.Node.DataDir)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	nodePassword, err := readPasswordFile(cfg.Node.PasswordFile)
+	if err != nil {
+		return fmt.Errorf("failed to read password file : %v", err)
+	}
+	if err := keystore.InitKeyStore(keystoreDir); err != nil {
+		return fmt.Errorf("failed to initialize keystore : %v", err)
+	}
+
+	return initializeFullNode(cfg, nodePassword)
+}
+
+// dumpconfig : 설정 파일, 환경 변수, 플래그를 반영한 최종 설정을 TOML로 출력
+func runDumpConfigCommand(args []string) error {
+	fs, f := newNodeFlagSet("dumpconfig")
+	fs.Parse(args)
+
+	cfg, err := f.load(fs)
+	if err != nil {
+		return err
+	}
+	return cfg.Encode(os.Stdout)
+}
+
+// bootnode : 부트스트랩 노드 실행
+func runBootnodeCommand(args []string) error {
+	fs := flag.NewFlagSet("bootnode", flag.ExitOnError)
+	configFile := fs.String("config", "", "TOML config file (uses bootnode.listen_addr)")
+	addr := fs.String("addr", "", "host:port on which the bootnode listens (default "+constants.BootstrapNodeAddress+")")
+	fs.Parse(args)
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		return err
+	}
+	if *addr != "" {
+		cfg.Bootnode.ListenAddr = *addr
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration : %v", err)
+	}
+
+	bootnode.StartBootstrapServer(cfg.Bootnode.ListenAddr)
+	return nil
+}
+
+// 비밀번호 파일의 첫 줄 (파일이 없으면 빈 비밀번호)
+func readPasswordFile(path string) (string, error) {
+	if path == "" {
+		utils.PrintError("[KEYSTORE] No -passwordfile given, using an empty password (testing only)")
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(strings.SplitN(string(data), "\n", 2)[0], "\r"), nil
+}
+
+func initializeFullNode(cfg *config.Config, nodePassword string) error {
+	tcpAddress := make(chan string)
+	udpAddress := make(chan string)
+
+	// 노드 계정 초기화
+	if err := blockchain.InitializeNodeAccount(nodePassword); err != nil {
+		return fmt.Errorf("failed to initialize node account : %v", err)
+	}
+
+	// Blockchain 초기화
+	if err := blockchain.InitGenesisBlock(); err != nil {
+		return fmt.Errorf("failed to initialize blockchain : %v", err)
+	}
+
+	if err := blockchain.PruneChain(); err != nil {
+		return fmt.Errorf("failed to prune chain : %v", err)
+	}
+
+	blockchain.InitMempool()
+	if err := blockchain.StartMempoolJournal(); err != nil {
+		return fmt.Errorf("failed to start mempool journal : %v", err)
+	}
+	blockchain.StartBlockchainProcessor()
+	leveldb.StartCompactionScheduler(cfg.Database.CompactionInterval)
+	blockchain.StartSnapshotter(filepath.Join(cfg.Node.DataDir, constants.SnapshotDir), cfg.Snapshot.Interval, cfg.Snapshot.Keep)
+	if cfg.RPC.Enabled {
+		go rpcserver.StartRpcServer(cfg.RPC.Host, cfg.RPC.Port, cfg.RPC.Namespaces)
+	}
+	go p2p.StartTCPServer(tcpAddress, cfg.P2P.ListenAddr)
+	go p2p.StartUDPServer(udpAddress, tcpAddress, cfg.P2P.ListenAddr)
+
+	udpServerAddress := <-udpAddress
+
+	// 부트노드를 순서대로 시도, 처음 응답한 부트노드의 피어 목록 사용 (부트노드가 없으면 연결을 기다림)
+	var nodeAddress []string
+	var err error
+	for _, bootstrapAddress := range cfg.P2P.Bootnodes {
+		nodeAddress, err = p2p.ConnectBootstrapNode(bootstrapAddress, udpServerAddress)
+		if err == nil {
+			break
+		}
+		utils.PrintError(fmt.Sprintf("Failed to connect to bootstrap node %s: %v", bootstrapAddress, err))
+	}
+	if err != nil {
+		// 모든 부트노드 연결 실패 : 블록 생성, TCP 서버는 계속 동작하고 들어오는 피어 연결을 기다림
+		utils.PrintError("Failed to connect to all bootstrap nodes, waiting for inbound peers")
+		nodeAddress = nil
+	}
+
+	fmt.Println("[Node Discovery] Peer addresses from bootnode:", nodeAddress)
+	if cfg.Mining.Enabled {
+		go blockchain.StartBlockCreator()
+	}
+	p2p.StartClient(nodeAddress)
+	return nil
+}
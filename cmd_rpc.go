@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CLI 명령이 사용하는 노드 RPC 기본 주소
+const defaultRPCEndpoint = "http://localhost:8080/rpc"
+
+type rpcRequest struct {
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+	ID     uint64        `json:"id"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  json.RawMessage `json:"error"`
+	ID     uint64          `json:"id"`
+}
+
+var rpcHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// /rpc 엔드포인트 호출 (gorilla JSON-RPC 형식), 결과를 reply에 디코딩
+func callRPC(endpoint, method string, args, reply interface{}) error {
+	body, err := json.Marshal(rpcRequest{Method: method, Params: []interface{}{args}, ID: 1})
+	if err != nil {
+		return err
+	}
+
+	resp, err := rpcHTTPClient.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to call %s : %v", method, err)
+	}
+	defer resp.Body.Close()
+
+	var response rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode %s response (status %s) : %v", method, resp.Status, err)
+	}
+	if len(response.Error) > 0 && string(response.Error) != "null" {
+		return decodeRPCError(method, response.Error)
+	}
+	return json.Unmarshal(response.Result, reply)
+}
+
+// 에러는 {code, message} 객체(트랜잭션 에러) 또는 문자열
+func decodeRPCError(method string, raw json.RawMessage) error {
+	var txErr struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &txErr); err == nil && txErr.Message != "" {
+		return fmt.Errorf("%s failed : %s (code %d)", method, txErr.Message, txErr.Code)
+	}
+	var message string
+	if err := json.Unmarshal(raw, &message); err == nil {
+		return fmt.Errorf("%s failed : %s", method, message)
+	}
+	return fmt.Errorf("%s failed : %s", method, raw)
+}
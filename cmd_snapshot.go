@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"simple_p2p_client/blockchain"
+	"simple_p2p_client/constants"
+)
+
+const snapshotUsage = `Usage: simple_p2p_client snapshot <command> [flags]
+
+Commands:
+  create    Save the state at the chain head to a snapshot file
+  restore   Initialize an empty data directory from a snapshot file`
+
+// snapshot : 상태 스냅샷 도구
+func runSnapshotCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", snapshotUsage)
+	}
+
+	switch args[0] {
+	case "create":
+		return snapshotCreate(args[1:])
+	case "restore":
+		return snapshotRestore(args[1:])
+	default:
+		return fmt.Errorf("unknown snapshot command %q\n%s", args[0], snapshotUsage)
+	}
+}
+
+func snapshotCreate(args []string) error {
+	fs := flag.NewFlagSet("snapshot create", flag.ExitOnError)
+	dataDir := dataDirFlag(fs)
+	out := fs.String("out", "", "Snapshot file (default <datadir>/snapshots/snapshot-<number>-<hash>.json.gz)")
+	fs.Parse(args)
+
+	closeDB, err := openDatabase(*dataDir)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	start := time.Now()
+	snapshot, err := blockchain.CreateSnapshot()
+	if err != nil {
+		return err
+	}
+
+	path := *out
+	if path == "" {
+		path = filepath.Join(*dataDir, constants.SnapshotDir, blockchain.SnapshotFileName(snapshot))
+	}
+	if err := blockchain.WriteSnapshotFile(path, snapshot); err != nil {
+		return err
+	}
+	fmt.Printf("Saved block %d (%s) state, %d accounts, to %s in %s\n",
+		snapshot.Number, snapshot.BlockHash, len(snapshot.Accounts), path, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+func snapshotRestore(args []string) error {
+	fs := flag.NewFlagSet("snapshot restore", flag.ExitOnError)
+	dataDir := dataDirFlag(fs)
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("Usage: simple_p2p_client snapshot restore [-datadir dir] <file>")
+	}
+
+	snapshot, err := blockchain.ReadSnapshotFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	closeDB, err := openDatabase(*dataDir)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	if err := blockchain.RestoreSnapshot(snapshot); err != nil {
+		return fmt.Errorf("failed to restore %s : %v", fs.Arg(0), err)
+	}
+	fmt.Printf("Restored block %d (%s) state, %d accounts, into %s\n", snapshot.Number, snapshot.BlockHash, len(snapshot.Accounts), *dataDir)
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+
+	"simple_p2p_client/account"
+	"simple_p2p_client/blockchain"
+	"simple_p2p_client/keystore"
+	"simple_p2p_client/utils"
+)
+
+const txUsage = `Usage: simple_p2p_client tx <command> [flags]
+
+Commands:
+  sign   Sign a transaction offline and print it as transaction.SendTransaction params
+  send   Sign a transaction with a keystore key and submit it to the node`
+
+// transaction.SendTransaction 파라미터와 같은 형태 (fee는 생략 가능)
+type signedTransaction struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Value     string `json:"value"`
+	Nonce     uint64 `json:"nonce"`
+	Fee       string `json:"fee,omitempty"`
+	Signature string `json:"signature"`
+}
+
+// tx 서브커맨드 : 트랜잭션 서명, 제출
+func runTxCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", txUsage)
+	}
+
+	switch args[0] {
+	case "sign":
+		return txSign(args[1:])
+	case "send":
+		return txSend(args[1:])
+	default:
+		return fmt.Errorf("unknown tx command %q\n%s", args[0], txUsage)
+	}
+}
+
+// tx sign, tx send 공통 플래그
+type txFlags struct {
+	from, to, value, fee string
+	keystoreDir          string
+	passwordFile         string
+}
+
+func (f *txFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.from, "from", "", "Sender address (key must be in the keystore)")
+	fs.StringVar(&f.to, "to", "", "Recipient address")
+	fs.StringVar(&f.value, "value", "", "Amount to send (decimal)")
+	fs.StringVar(&f.fee, "fee", "", "Optional fee (decimal)")
+	fs.StringVar(&f.keystoreDir, "keystore", defaultKeyStoreDir, "Keystore directory")
+	fs.StringVar(&f.passwordFile, "passwordfile", "", "File containing the key password")
+}
+
+func txSign(args []string) error {
+	fs := flag.NewFlagSet("tx sign", flag.ExitOnError)
+	var f txFlags
+	f.register(fs)
+	nonce := fs.Uint64("nonce", 0, "Transaction nonce (required, last used nonce + 1)")
+	fs.Parse(args)
+
+	if *nonce == 0 {
+		return fmt.Errorf("-nonce is required for offline signing")
+	}
+	tx, err := signTransaction(&f, *nonce)
+	if err != nil {
+		return err
+	}
+
+	output, err := json.MarshalIndent(tx, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
+
+func txSend(args []string) error {
+	fs := flag.NewFlagSet("tx send", flag.ExitOnError)
+	var f txFlags
+	f.register(fs)
+	nonce := fs.Uint64("nonce", 0, "Transaction nonce (fetched from the node's pending state if not set)")
+	endpoint := fs.String("rpc", defaultRPCEndpoint, "Node RPC endpoint")
+	fs.Parse(args)
+
+	// nonce가 없으면 멤풀 pending까지 반영한 다음 nonce 조회
+	if *nonce == 0 {
+		var pending struct {
+			NextNonce uint64 `json:"nextNonce"`
+		}
+		if err := callRPC(*endpoint, "account.GetPendingNonce", map[string]string{"address": f.from}, &pending); err != nil {
+			return err
+		}
+		*nonce = pending.NextNonce
+	}
+
+	tx, err := signTransaction(&f, *nonce)
+	if err != nil {
+		return err
+	}
+
+	var reply struct {
+		TxHash string `json:"txHash"`
+	}
+	if err := callRPC(*endpoint, "transaction.SendTransaction", tx, &reply); err != nil {
+		return err
+	}
+	fmt.Printf("Transaction submitted : %s (nonce %d)\n", reply.TxHash, tx.Nonce)
+	return nil
+}
+
+// 키 저장소의 키로 ProcessTransaction이 검증하는 것과 같은 해시에 서명
+func signTransaction(f *txFlags, nonce uint64) (*signedTransaction, error) {
+	if !account.IsValidAddress(f.from) || !account.IsValidAddress(f.to) {
+		return nil, fmt.Errorf("-from and -to must be valid addresses")
+	}
+	value, err := utils.ConvertStringToBigInt(f.value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -value : %v", err)
+	}
+	var fee *big.Int
+	if f.fee != "" {
+		if fee, err = utils.ConvertStringToBigInt(f.fee); err != nil {
+			return nil, fmt.Errorf("invalid -fee : %v", err)
+		}
+	}
+
+	password, err := readPasswordFile(f.passwordFile)
+	if err != nil {
+		return nil, err
+	}
+	ks, err := keystore.NewKeyStore(f.keystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return nil, err
+	}
+
+	messageHash := blockchain.TransactionSigningHash(f.from, f.to, value, nonce, fee)
+	signature, err := ks.SignHashWithPassword(f.from, password, messageHash)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &signedTransaction{
+		From:      f.from,
+		To:        f.to,
+		Value:     value.String(),
+		Nonce:     nonce,
+		Signature: signature,
+	}
+	if fee != nil {
+		tx.Fee = fee.String()
+	}
+	return tx, nil
+}
+
+// balance 서브커맨드 : 노드에서 계정 잔액, nonce 조회
+func runBalanceCommand(args []string) error {
+	fs := flag.NewFlagSet("balance", flag.ExitOnError)
+	endpoint := fs.String("rpc", defaultRPCEndpoint, "Node RPC endpoint")
+	block := fs.String("block", "", "Block number or hash to query the state as of that block (default latest)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("Usage: simple_p2p_client balance [-rpc endpoint] [-block number|hash] <address>")
+	}
+	address := fs.Arg(0)
+
+	var info struct {
+		Balance     string `json:"balance"`
+		Nonce       uint64 `json:"nonce"`
+		BlockNumber uint64 `json:"blockNumber"`
+	}
+	if err := callRPC(*endpoint, "account.GetAccountInfo", map[string]string{"address": address, "block": *block}, &info); err != nil {
+		return err
+	}
+
+	// 과거 상태는 pending 정보 없이 출력
+	if *block != "" && *block != "latest" {
+		fmt.Printf("Address         : %s\n", address)
+		fmt.Printf("Block           : %d\n", info.BlockNumber)
+		fmt.Printf("Balance         : %s\n", info.Balance)
+		fmt.Printf("Nonce           : %d\n", info.Nonce)
+		return nil
+	}
+	var pending struct {
+		NextNonce uint64 `json:"nextNonce"`
+		Balance   string `json:"balance"`
+	}
+	if err := callRPC(*endpoint, "account.GetPendingNonce", map[string]string{"address": address}, &pending); err != nil {
+		return err
+	}
+
+	fmt.Printf("Address         : %s\n", address)
+	fmt.Printf("Balance         : %s\n", info.Balance)
+	fmt.Printf("Nonce           : %d\n", info.Nonce)
+	fmt.Printf("Pending balance : %s\n", pending.Balance)
+	fmt.Printf("Next nonce      : %d\n", pending.NextNonce)
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"simple_p2p_client/keystore"
+	"simple_p2p_client/wallet"
+)
+
+const walletUsage = `Usage: simple_p2p_client wallet <command> [flags]
+
+Commands:
+  new      Generate a new BIP-39 mnemonic and print its first address
+  derive   Print addresses derived along m/44'/60'/0'/0/i
+  import   Encrypt derived keys into the keystore`
+
+// wallet 서브커맨드 : 니모닉 생성, 주소 유도, 키 저장소로 가져오기
+func runWalletCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", walletUsage)
+	}
+
+	switch args[0] {
+	case "new":
+		return walletNew(args[1:])
+	case "derive":
+		return walletDerive(args[1:])
+	case "import":
+		return walletImport(args[1:])
+	default:
+		return fmt.Errorf("unknown wallet command %q\n%s", args[0], walletUsage)
+	}
+}
+
+func walletNew(args []string) error {
+	fs := flag.NewFlagSet("wallet new", flag.ExitOnError)
+	words := fs.Int("words", 12, "Number of mnemonic words (12 or 24)")
+	fs.Parse(args)
+
+	mnemonic, err := wallet.NewMnemonic(*words)
+	if err != nil {
+		return err
+	}
+	w, err := wallet.NewFromMnemonic(mnemonic, "")
+	if err != nil {
+		return err
+	}
+	first, err := w.Derive(wallet.AccountPath(0))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Mnemonic (write it down and keep it secret) :")
+	fmt.Println(mnemonic)
+	fmt.Printf("First address (%s) : %s\n", first.Path, first.Address)
+	return nil
+}
+
+func walletDerive(args []string) error {
+	fs := flag.NewFlagSet("wallet derive", flag.ExitOnError)
+	mnemonicFile := fs.String("mnemonicfile", "", "File containing the mnemonic (read from stdin if not set)")
+	passphrase := fs.String("passphrase", "", "Optional BIP-39 passphrase")
+	start := fs.Uint("start", 0, "First account index")
+	count := fs.Uint("count", 5, "Number of accounts")
+	fs.Parse(args)
+
+	w, err := openWallet(*mnemonicFile, *passphrase)
+	if err != nil {
+		return err
+	}
+	accounts, err := w.DeriveAccounts(uint32(*start), uint32(*count))
+	if err != nil {
+		return err
+	}
+	for _, derived := range accounts {
+		fmt.Printf("%s  %s\n", derived.Path, derived.Address)
+	}
+	return nil
+}
+
+func walletImport(args []string) error {
+	fs := flag.NewFlagSet("wallet import", flag.ExitOnError)
+	mnemonicFile := fs.String("mnemonicfile", "", "File containing the mnemonic (read from stdin if not set)")
+	passphrase := fs.String("passphrase", "", "Optional BIP-39 passphrase")
+	start := fs.Uint("start", 0, "First account index")
+	count := fs.Uint("count", 1, "Number of accounts")
+	keystoreDir := fs.String("keystore", defaultKeyStoreDir, "Keystore directory to import into")
+	passwordFile := fs.String("passwordfile", "", "File containing the password used to encrypt the keys")
+	fs.Parse(args)
+
+	w, err := openWallet(*mnemonicFile, *passphrase)
+	if err != nil {
+		return err
+	}
+	password, err := readPasswordFile(*passwordFile)
+	if err != nil {
+		return err
+	}
+	ks, err := keystore.NewKeyStore(*keystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return err
+	}
+
+	imported, err := w.ImportToKeyStore(ks, uint32(*start), uint32(*count), password)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d accounts into %s\n", len(imported), *keystoreDir)
+	for _, address := range imported {
+		fmt.Println(address)
+	}
+	return nil
+}
+
+// 파일(없으면 표준 입력 첫 줄)에서 니모닉을 읽어 지갑 생성
+func openWallet(mnemonicFile, passphrase string) (*wallet.Wallet, error) {
+	var mnemonic string
+	if mnemonicFile != "" {
+		data, err := os.ReadFile(mnemonicFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mnemonic file : %v", err)
+		}
+		mnemonic = string(data)
+	} else {
+		fmt.Fprint(os.Stderr, "Enter mnemonic : ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return nil, fmt.Errorf("failed to read mnemonic : %v", err)
+		}
+		mnemonic = line
+	}
+	return wallet.NewFromMnemonic(strings.TrimSpace(mnemonic), passphrase)
+}
@@ -0,0 +1,300 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"simple_p2p_client/constants"
+	"simple_p2p_client/p2p"
+
+	"github.com/BurntSushi/toml"
+)
+
+// 환경 변수 접두사 : SBC_<섹션>_<키> (예 : SBC_RPC_PORT=8081)
+const EnvPrefix = "SBC"
+
+// RPC 네임스페이스 (eth는 이더리움 호환 엔드포인트 /와 웹소켓 /ws, db는 컴팩션 등 관리용)
+var AllNamespaces = []string{"rpc", "block", "transaction", "account", "txpool", "chain", "personal", "eth", "db"}
+
+// 기본으로 켜는 네임스페이스 (관리용 db 제외)
+var DefaultNamespaces = []string{"rpc", "block", "transaction", "account", "txpool", "chain", "personal", "eth"}
+
+// 노드 설정 : 기본값 < 설정 파일 < 환경 변수 < 명령줄 플래그 순으로 덮어씀
+type Config struct {
+	Node     NodeConfig     `toml:"node"`
+	P2P      P2PConfig      `toml:"p2p"`
+	RPC      RPCConfig      `toml:"rpc"`
+	Mining   MiningConfig   `toml:"mining"`
+	Mempool  MempoolConfig  `toml:"mempool"`
+	State    StateConfig    `toml:"state"`
+	Database DatabaseConfig `toml:"database"`
+	Snapshot SnapshotConfig `toml:"snapshot"`
+	Bootnode BootnodeConfig `toml:"bootnode"`
+}
+
+type NodeConfig struct {
+	DataDir      string `toml:"datadir"`       // DB, 키 저장소, 멤풀 저널 디렉토리
+	Keystore     string `toml:"keystore"`      // 비어 있으면 <datadir>/keystore
+	PasswordFile string `toml:"password_file"` // 노드 계정 키 비밀번호 파일
+	Genesis      string `toml:"genesis"`       // 체인을 처음 만들 때 사용할 제네시스 설정 파일
+}
+
+type P2PConfig struct {
+	ListenAddr string   `toml:"listen_addr"` // TCP, UDP(Node Discovery) 서버 주소
+	Bootnodes  []string `toml:"bootnodes"`   // 순서대로 연결을 시도할 부트노드 주소
+	MaxPeers   int      `toml:"max_peers"`   // 0이면 제한 없음
+	NAT        string   `toml:"nat"`         // 다른 노드에 알릴 외부 주소 : none 또는 extip:<ip>
+}
+
+type RPCConfig struct {
+	Enabled    bool     `toml:"enabled"`
+	Host       string   `toml:"host"` // 비어 있으면 모든 인터페이스
+	Port       int      `toml:"port"`
+	Namespaces []string `toml:"namespaces"`
+}
+
+type MiningConfig struct {
+	Enabled       bool          `toml:"enabled"`
+	BlockInterval time.Duration `toml:"block_interval"` // 블록 생성 시도 주기
+	TxsPerBlock   int           `toml:"txs_per_block"`  // 블록 당 트랜잭션 개수
+}
+
+type MempoolConfig struct {
+	PriceBump           uint64        `toml:"price_bump"` // 교체에 필요한 최소 수수료 인상률(%)
+	GlobalPendingSlots  int           `toml:"global_pending_slots"`
+	GlobalFutureSlots   int           `toml:"global_future_slots"`
+	AccountPendingSlots int           `toml:"account_pending_slots"`
+	AccountFutureSlots  int           `toml:"account_future_slots"`
+	FutureLifetime      time.Duration `toml:"future_lifetime"`
+	JournalRotation     time.Duration `toml:"journal_rotation"`
+}
+
+type StateConfig struct {
+	HistoryWindow uint64 `toml:"history_window"` // 과거 계정 상태를 남길 최근 블록 수 (0이면 아카이브 : 모든 블록)
+	PruneBlocks   uint64 `toml:"prune_blocks"`   // 트랜잭션(본문)을 남길 최근 블록 수, 헤더는 항상 유지 (0이면 정리하지 않음)
+}
+
+// 본문을 정리하면 상태 이력도 같은 범위까지만 유지
+func (c *StateConfig) EffectiveHistoryWindow() uint64 {
+	if c.PruneBlocks > 0 && (c.HistoryWindow == 0 || c.HistoryWindow > c.PruneBlocks) {
+		return c.PruneBlocks
+	}
+	return c.HistoryWindow
+}
+
+type DatabaseConfig struct {
+	CompactionInterval time.Duration `toml:"compaction_interval"` // 전체 컴팩션 주기 (0이면 사용하지 않음)
+}
+
+type SnapshotConfig struct {
+	Interval uint64 `toml:"interval"` // 스냅샷을 저장할 블록 간격 (0이면 저장하지 않음)
+	Keep     int    `toml:"keep"`     // 남겨 둘 스냅샷 파일 수 (0이면 모두 남김)
+}
+
+type BootnodeConfig struct {
+	ListenAddr string `toml:"listen_addr"`
+}
+
+// constants 패키지 값과 같은 기본 설정
+func Default() *Config {
+	return &Config{
+		Node: NodeConfig{
+			DataDir: "./db/default",
+		},
+		P2P: P2PConfig{
+			ListenAddr: "127.0.0.1:30303",
+			Bootnodes:  []string{constants.BootstrapNodeAddress},
+			MaxPeers:   constants.DefaultMaxPeers,
+			NAT:        "none",
+		},
+		RPC: RPCConfig{
+			Enabled:    true,
+			Port:       8080,
+			Namespaces: append([]string{}, DefaultNamespaces...),
+		},
+		Mining: MiningConfig{
+			Enabled:       true,
+			BlockInterval: constants.BlockCreationInterval,
+			TxsPerBlock:   constants.TransactionsPerBlock,
+		},
+		Mempool: MempoolConfig{
+			PriceBump:           constants.DefaultPriceBump,
+			GlobalPendingSlots:  constants.MempoolGlobalPendingSlots,
+			GlobalFutureSlots:   constants.MempoolGlobalFutureSlots,
+			AccountPendingSlots: constants.MempoolAccountPendingSlots,
+			AccountFutureSlots:  constants.MempoolAccountFutureSlots,
+			FutureLifetime:      constants.MempoolFutureLifetime,
+			JournalRotation:     constants.MempoolJournalRotation,
+		},
+		Snapshot: SnapshotConfig{
+			Keep: constants.DefaultSnapshotKeep,
+		},
+		Bootnode: BootnodeConfig{
+			ListenAddr: constants.BootstrapNodeAddress,
+		},
+	}
+}
+
+// 기본값에 설정 파일(path가 비어 있으면 생략), 환경 변수를 차례로 적용
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		if err := cfg.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// TOML 설정 파일 적용 (파일에 있는 키만 덮어씀, 모르는 키는 에러)
+func (c *Config) LoadFile(path string) error {
+	meta, err := toml.DecodeFile(path, c)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s : %v", path, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("unknown config keys in %s : %v", path, undecoded)
+	}
+	return nil
+}
+
+// SBC_<섹션>_<키> 환경 변수 적용 (목록은 쉼표로 구분)
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	sections := reflect.ValueOf(c).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		sectionName := sections.Type().Field(i).Tag.Get("toml")
+
+		for j := 0; j < section.NumField(); j++ {
+			key := section.Type().Field(j).Tag.Get("toml")
+			name := strings.ToUpper(EnvPrefix + "_" + sectionName + "_" + key)
+			value, exists := lookup(name)
+			if !exists {
+				continue
+			}
+			if err := setField(section.Field(j), value); err != nil {
+				return fmt.Errorf("invalid %s : %v", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+	case int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+	case []string:
+		list := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// 설정 값 검증
+func (c *Config) Validate() error {
+	if c.Node.DataDir == "" {
+		return fmt.Errorf("node.datadir must not be empty")
+	}
+	if _, _, err := net.SplitHostPort(c.P2P.ListenAddr); err != nil {
+		return fmt.Errorf("invalid p2p.listen_addr %q : %v", c.P2P.ListenAddr, err)
+	}
+	if _, _, err := net.SplitHostPort(c.Bootnode.ListenAddr); err != nil {
+		return fmt.Errorf("invalid bootnode.listen_addr %q : %v", c.Bootnode.ListenAddr, err)
+	}
+	if _, err := p2p.ParseNAT(c.P2P.NAT); err != nil {
+		return fmt.Errorf("invalid p2p.nat : %v", err)
+	}
+	if c.P2P.MaxPeers < 0 {
+		return fmt.Errorf("p2p.max_peers must not be negative")
+	}
+	if c.RPC.Port <= 0 || c.RPC.Port > 65535 {
+		return fmt.Errorf("invalid rpc.port %d", c.RPC.Port)
+	}
+	for _, namespace := range c.RPC.Namespaces {
+		if !isKnownNamespace(namespace) {
+			return fmt.Errorf("unknown rpc namespace %q, available : %s", namespace, strings.Join(AllNamespaces, ", "))
+		}
+	}
+	// 블록 간 최소 간격보다 짧으면 매 주기 블록 생성을 건너뜀
+	if c.Mining.BlockInterval < constants.MinBlockSpacing {
+		return fmt.Errorf("mining.block_interval must be at least %s", constants.MinBlockSpacing)
+	}
+	if c.Mining.TxsPerBlock <= 0 || c.Mining.TxsPerBlock > constants.MaxTransactionsPerBlock {
+		return fmt.Errorf("mining.txs_per_block must be between 1 and %d", constants.MaxTransactionsPerBlock)
+	}
+	if c.Mempool.JournalRotation <= 0 {
+		return fmt.Errorf("mempool.journal_rotation must be positive")
+	}
+	if c.Database.CompactionInterval < 0 {
+		return fmt.Errorf("database.compaction_interval must not be negative")
+	}
+	if c.Snapshot.Keep < 0 {
+		return fmt.Errorf("snapshot.keep must not be negative")
+	}
+	return nil
+}
+
+func isKnownNamespace(namespace string) bool {
+	for _, known := range AllNamespaces {
+		if namespace == known {
+			return true
+		}
+	}
+	return false
+}
+
+// RPC 네임스페이스가 켜져 있는지
+func (c *RPCConfig) HasNamespace(namespace string) bool {
+	for _, enabled := range c.Namespaces {
+		if enabled == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// TOML로 출력 (dumpconfig)
+func (c *Config) Encode(w io.Writer) error {
+	return toml.NewEncoder(w).Encode(c)
+}
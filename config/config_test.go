@@ -0,0 +1,128 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadFileAndEnvOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "node.toml")
+	content := `
+[p2p]
+bootnodes = ["127.0.0.1:8282", "127.0.0.1:8283"]
+
+[rpc]
+port = 9000
+namespaces = ["block", "eth"]
+
+[mining]
+block_interval = "20s"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Default()
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed : %v", err)
+	}
+
+	env := map[string]string{
+		"SBC_RPC_PORT":       "9100",
+		"SBC_P2P_BOOTNODES":  "10.0.0.1:8282, 10.0.0.2:8282",
+		"SBC_MINING_ENABLED": "false",
+	}
+	lookup := func(name string) (string, bool) {
+		value, exists := env[name]
+		return value, exists
+	}
+	if err := cfg.ApplyEnv(lookup); err != nil {
+		t.Fatalf("ApplyEnv failed : %v", err)
+	}
+
+	if cfg.RPC.Port != 9100 {
+		t.Errorf("expected env to override rpc.port, got %d", cfg.RPC.Port)
+	}
+	if len(cfg.P2P.Bootnodes) != 2 || cfg.P2P.Bootnodes[1] != "10.0.0.2:8282" {
+		t.Errorf("unexpected bootnodes : %v", cfg.P2P.Bootnodes)
+	}
+	if cfg.Mining.Enabled {
+		t.Error("expected mining to be disabled by env")
+	}
+	if cfg.Mining.BlockInterval != 20*time.Second {
+		t.Errorf("expected block interval 20s from file, got %s", cfg.Mining.BlockInterval)
+	}
+	if !cfg.RPC.HasNamespace("eth") || cfg.RPC.HasNamespace("personal") {
+		t.Errorf("unexpected namespaces : %v", cfg.RPC.Namespaces)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate failed : %v", err)
+	}
+
+	// dumpconfig 출력은 다시 읽을 수 있어야 함
+	var buf bytes.Buffer
+	if err := cfg.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dumped := filepath.Join(t.TempDir(), "dumped.toml")
+	if err := os.WriteFile(dumped, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := Default()
+	if err := reloaded.LoadFile(dumped); err != nil {
+		t.Fatalf("failed to reload dumped config : %v", err)
+	}
+	if reloaded.RPC.Port != 9100 || reloaded.Mining.BlockInterval != 20*time.Second || reloaded.Mining.Enabled {
+		t.Errorf("dumped config does not round trip : %+v", reloaded)
+	}
+}
+
+func TestValidateRejectsBadValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{"unknown namespace", func(c *Config) { c.RPC.Namespaces = []string{"admin"} }},
+		{"short block interval", func(c *Config) { c.Mining.BlockInterval = time.Second }},
+		{"listen address without port", func(c *Config) { c.P2P.ListenAddr = "127.0.0.1" }},
+		{"negative max peers", func(c *Config) { c.P2P.MaxPeers = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+			if err := cfg.Validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "unknown.toml")
+	if err := os.WriteFile(path, []byte("[rpc]\nportt = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Default().LoadFile(path); err == nil {
+		t.Error("expected error for unknown config key")
+	}
+}
+
+func TestEffectiveHistoryWindow(t *testing.T) {
+	tests := []struct {
+		historyWindow, pruneBlocks, expected uint64
+	}{
+		{0, 0, 0},     // 아카이브
+		{100, 0, 100}, // 본문은 모두 유지, 상태 이력만 정리
+		{0, 50, 50},   // 본문을 정리하면 상태 이력도 같은 범위
+		{100, 50, 50}, // 본문 범위보다 길게 남기지 않음
+		{20, 50, 20},  // 더 짧은 상태 이력 범위는 유지
+	}
+	for _, tt := range tests {
+		c := StateConfig{HistoryWindow: tt.historyWindow, PruneBlocks: tt.pruneBlocks}
+		if got := c.EffectiveHistoryWindow(); got != tt.expected {
+			t.Errorf("history_window %d, prune_blocks %d : expected %d, got %d", tt.historyWindow, tt.pruneBlocks, tt.expected, got)
+		}
+	}
+}
@@ -3,7 +3,29 @@ package constants
 import "time"
 
 const (
-	TransactionsPerBlock  = 5                // 블록 당 트랜잭션 개수
-	BlockCreationInterval = 10 * time.Second // 블록 생성 주기
-	BootstrapNodeAddress  = "localhost:8282" // 하드코딩된 부트스트랩 노드 주소
+	TransactionsPerBlock    = 5                // 블록 당 트랜잭션 개수
+	MaxTransactionsPerBlock = 100              // 받은 블록에 허용하는 최대 트랜잭션 개수
+	BlockCreationInterval   = 10 * time.Second // 블록 생성 주기
+	MinBlockSpacing         = 5 * time.Second  // 부모 블록과의 최소 타임스탬프 간격 (블록 생성 주기의 절반)
+	MaxBlockFutureDrift     = 15 * time.Second // 로컬 시각보다 앞선 타임스탬프 허용 범위
+	BootstrapNodeAddress    = "localhost:8282" // 하드코딩된 부트스트랩 노드 주소
+	DefaultPriceBump        = 10               // 같은 nonce 트랜잭션 교체에 필요한 최소 수수료 인상률(%)
+	DefaultMaxPeers         = 25               // 연결을 유지할 최대 피어 수 (0이면 제한 없음)
+
+	MempoolGlobalPendingSlots  = 4096                   // 멤풀 전체 pending 최대 트랜잭션 수
+	MempoolGlobalFutureSlots   = 1024                   // 멤풀 전체 future 최대 트랜잭션 수
+	MempoolAccountPendingSlots = 64                     // 주소 하나의 pending 최대 트랜잭션 수
+	MempoolAccountFutureSlots  = 16                     // 주소 하나의 future 최대 트랜잭션 수
+	MempoolFutureLifetime      = 3 * time.Hour          // future 트랜잭션이 pending으로 옮겨지지 못하고 머무를 수 있는 최대 시간
+	MempoolJournalFile         = "transactions.journal" // 로컬 트랜잭션 저널 파일 이름 (DB 디렉토리 안)
+	MempoolJournalRotation     = time.Hour              // 저널을 멤풀에 남은 트랜잭션으로 다시 쓰는 주기
+
+	KeyStoreDir = "keystore" // 암호화된 키 파일 디렉토리 이름 (DB 디렉토리 안)
+
+	SnapshotDir         = "snapshots" // 주기적 상태 스냅샷 디렉토리 이름 (DB 디렉토리 안)
+	DefaultSnapshotKeep = 2           // 남겨 둘 주기적 스냅샷 파일 수
+
+	ChainID       = 1337                       // eth_chainId, net_version으로 반환하는 체인 ID
+	ClientName    = "simple-blockchain-client" // web3_clientVersion 클라이언트 이름
+	ClientVersion = "v1.0.0"                   // web3_clientVersion 클라이언트 버전
 )
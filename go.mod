@@ -5,10 +5,13 @@ go 1.22
 toolchain go1.23.2
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/decred/dcrd/dcrec/secp256k1 v1.0.4
 	github.com/ethereum/go-ethereum v1.14.11
 	github.com/gorilla/rpc v1.2.1
+	github.com/gorilla/websocket v1.5.3
 	github.com/syndtr/goleveldb v1.0.1-0.20210819022825-2ae1ddf74ef7
+	github.com/tyler-smith/go-bip39 v1.1.0
 	golang.org/x/crypto v0.28.0
 )
 
@@ -0,0 +1,245 @@
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"simple_p2p_client/account"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrNoMatch = errors.New("no key for given address")
+	ErrLocked  = errors.New("account is locked")
+	ErrExists  = errors.New("account already exists in keystore")
+)
+
+// 잠금 해제된 키
+type unlockedKey struct {
+	privateKey *ecdsa.PrivateKey
+	expiresAt  time.Time // zero면 잠글 때까지 유지
+}
+
+// 암호화된 키 파일을 보관하는 디렉토리, 잠금 해제된 키는 메모리에만 보관
+type KeyStore struct {
+	dir      string
+	scryptN  int
+	scryptP  int
+	mu       sync.Mutex
+	unlocked map[string]*unlockedKey // 소문자 주소 => 키
+}
+
+var defaultKeyStore *KeyStore
+
+// 키 저장소 디렉토리 생성, 기본 키 저장소로 설정
+func InitKeyStore(dir string) error {
+	ks, err := NewKeyStore(dir, StandardScryptN, StandardScryptP)
+	if err != nil {
+		return err
+	}
+	defaultKeyStore = ks
+	fmt.Printf("[KEYSTORE] Initialized : %s\n", dir)
+	return nil
+}
+
+// 기본 키 저장소 (InitKeyStore 전에는 nil)
+func GetKeyStore() *KeyStore {
+	return defaultKeyStore
+}
+
+func NewKeyStore(dir string, scryptN, scryptP int) (*KeyStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create keystore directory : %v", err)
+	}
+	return &KeyStore{
+		dir:      dir,
+		scryptN:  scryptN,
+		scryptP:  scryptP,
+		unlocked: make(map[string]*unlockedKey),
+	}, nil
+}
+
+// 새 키를 만들어 비밀번호로 암호화해 저장, 주소 반환
+func (ks *KeyStore) NewAccount(password string) (string, error) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate key : %v", err)
+	}
+	return ks.ImportECDSA(privateKey, password)
+}
+
+// 기존 개인키를 비밀번호로 암호화해 저장, 주소 반환
+func (ks *KeyStore) ImportECDSA(privateKey *ecdsa.PrivateKey, password string) (string, error) {
+	address, err := account.PublicKeyToAddress(crypto.FromECDSAPub(&privateKey.PublicKey))
+	if err != nil {
+		return "", err
+	}
+	if _, err := ks.find(address); err == nil {
+		return "", fmt.Errorf("%w : %s", ErrExists, address)
+	}
+
+	keyJSON, err := EncryptKey(privateKey, address, password, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt key : %v", err)
+	}
+
+	// geth와 같은 파일 이름 : UTC--<생성 시각>--<주소>
+	fileName := fmt.Sprintf("UTC--%s--%s", time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z"), strings.TrimPrefix(address, "0x"))
+	if err := os.WriteFile(filepath.Join(ks.dir, fileName), keyJSON, 0600); err != nil {
+		return "", fmt.Errorf("failed to write key file : %v", err)
+	}
+
+	fmt.Printf("[KEYSTORE] New key stored : %s\n", address)
+	return address, nil
+}
+
+// 키 저장소의 모든 주소 (정렬)
+func (ks *KeyStore) Accounts() ([]string, error) {
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore directory : %v", err)
+	}
+
+	addresses := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		address, err := readKeyAddress(filepath.Join(ks.dir, entry.Name()))
+		if err != nil {
+			continue // 키 파일이 아닌 파일은 무시
+		}
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses, nil
+}
+
+// 주소가 키 저장소에 있는지
+func (ks *KeyStore) HasAccount(address string) bool {
+	_, err := ks.find(address)
+	return err == nil
+}
+
+// 비밀번호로 키를 복호화해 timeout 동안 잠금 해제 (0이면 Lock 호출 전까지)
+func (ks *KeyStore) Unlock(address, password string, timeout time.Duration) error {
+	privateKey, err := ks.decrypt(address, password)
+	if err != nil {
+		return err
+	}
+
+	unlocked := &unlockedKey{privateKey: privateKey}
+	if timeout > 0 {
+		unlocked.expiresAt = time.Now().Add(timeout)
+	}
+
+	ks.mu.Lock()
+	ks.unlocked[strings.ToLower(address)] = unlocked
+	ks.mu.Unlock()
+	return nil
+}
+
+// 잠금 해제된 키를 메모리에서 제거
+func (ks *KeyStore) Lock(address string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	delete(ks.unlocked, strings.ToLower(address))
+}
+
+// 잠금 해제되어 있는지 (만료된 키는 제거)
+func (ks *KeyStore) IsUnlocked(address string) bool {
+	_, err := ks.unlockedKey(address)
+	return err == nil
+}
+
+// 잠금 해제된 키로 해시 서명 (16진수 서명 반환)
+func (ks *KeyStore) SignHash(address string, hash []byte) (string, error) {
+	privateKey, err := ks.unlockedKey(address)
+	if err != nil {
+		return "", err
+	}
+	return account.SignMessage(hash, privateKey)
+}
+
+// 비밀번호로 복호화해 한 번만 서명 (잠금 해제 상태는 바꾸지 않음)
+func (ks *KeyStore) SignHashWithPassword(address, password string, hash []byte) (string, error) {
+	privateKey, err := ks.decrypt(address, password)
+	if err != nil {
+		return "", err
+	}
+	return account.SignMessage(hash, privateKey)
+}
+
+func (ks *KeyStore) unlockedKey(address string) (*ecdsa.PrivateKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	key := strings.ToLower(address)
+	unlocked, exists := ks.unlocked[key]
+	if !exists {
+		return nil, fmt.Errorf("%w : %s", ErrLocked, address)
+	}
+	if !unlocked.expiresAt.IsZero() && time.Now().After(unlocked.expiresAt) {
+		delete(ks.unlocked, key)
+		return nil, fmt.Errorf("%w : %s", ErrLocked, address)
+	}
+	return unlocked.privateKey, nil
+}
+
+func (ks *KeyStore) decrypt(address, password string) (*ecdsa.PrivateKey, error) {
+	path, err := ks.find(address)
+	if err != nil {
+		return nil, err
+	}
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file : %v", err)
+	}
+	return DecryptKey(keyJSON, password)
+}
+
+// 주소의 키 파일 경로
+func (ks *KeyStore) find(address string) (string, error) {
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read keystore directory : %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(ks.dir, entry.Name())
+		fileAddress, err := readKeyAddress(path)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(fileAddress, address) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%w : %s", ErrNoMatch, address)
+}
+
+// 키 파일의 주소 (0x 접두사)
+func readKeyAddress(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var keyFile struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(data, &keyFile); err != nil || keyFile.Address == "" {
+		return "", fmt.Errorf("not a key file : %s", path)
+	}
+	return "0x" + strings.ToLower(strings.TrimPrefix(keyFile.Address, "0x")), nil
+}
@@ -0,0 +1,75 @@
+package keystore
+
+import (
+	"errors"
+	"simple_p2p_client/account"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestEncryptDecryptKey(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	address, _ := account.PublicKeyToAddress(crypto.FromECDSAPub(&privateKey.PublicKey))
+
+	keyJSON, err := EncryptKey(privateKey, address, "secret", LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatalf("EncryptKey failed: %v", err)
+	}
+
+	decrypted, err := DecryptKey(keyJSON, "secret")
+	if err != nil {
+		t.Fatalf("DecryptKey failed: %v", err)
+	}
+	if decrypted.D.Cmp(privateKey.D) != 0 {
+		t.Fatalf("decrypted key does not match")
+	}
+
+	if _, err := DecryptKey(keyJSON, "wrong"); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt with wrong password, got %v", err)
+	}
+}
+
+func TestKeyStoreUnlockAndSign(t *testing.T) {
+	ks, err := NewKeyStore(t.TempDir(), LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatalf("NewKeyStore failed: %v", err)
+	}
+
+	address, err := ks.NewAccount("secret")
+	if err != nil {
+		t.Fatalf("NewAccount failed: %v", err)
+	}
+	accounts, err := ks.Accounts()
+	if err != nil || len(accounts) != 1 || accounts[0] != strings.ToLower(address) {
+		t.Fatalf("unexpected accounts %v (err %v)", accounts, err)
+	}
+
+	hash := crypto.Keccak256([]byte("message"))
+	if _, err := ks.SignHash(address, hash); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked before unlock, got %v", err)
+	}
+	if err := ks.Unlock(address, "wrong", 0); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt with wrong password, got %v", err)
+	}
+
+	if err := ks.Unlock(address, "secret", 50*time.Millisecond); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if _, err := ks.SignHash(address, hash); err != nil {
+		t.Fatalf("SignHash failed after unlock: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if ks.IsUnlocked(address) {
+		t.Fatalf("expected key to be locked after timeout")
+	}
+	if _, err := ks.SignHash(address, hash); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked after timeout, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"simple_p2p_client/utils"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Web3 Secret Storage (version 3) 파라미터
+const (
+	StandardScryptN = 1 << 18 // 키 파일 기본 scrypt 비용
+	StandardScryptP = 1
+	LightScryptN    = 1 << 12 // 테스트용 가벼운 비용
+	LightScryptP    = 6
+
+	scryptR     = 8
+	scryptDKLen = 32
+)
+
+// 비밀번호가 틀렸거나 키 파일이 손상됨
+var ErrDecrypt = errors.New("could not decrypt key with given password")
+
+// 키 파일 JSON (Web3 Secret Storage v3, geth, MetaMask와 호환)
+type encryptedKeyJSON struct {
+	Address string     `json:"address"` // 0x 없는 소문자 주소
+	Crypto  cryptoJSON `json:"crypto"`
+	ID      string     `json:"id"`
+	Version int        `json:"version"`
+}
+
+type cryptoJSON struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams cipherParamsJSON       `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type cipherParamsJSON struct {
+	IV string `json:"iv"`
+}
+
+// 개인키를 비밀번호로 암호화해 키 파일 JSON 반환 (scrypt + AES-128-CTR, MAC은 Keccak256)
+func EncryptKey(privateKey *ecdsa.PrivateKey, address, password string, scryptN, scryptP int) ([]byte, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to read random salt : %v", err)
+	}
+	derivedKey, err := scrypt.Key([]byte(password), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("failed to read random iv : %v", err)
+	}
+	cipherText, err := aesCTRXOR(derivedKey[:16], crypto.FromECDSA(privateKey), iv)
+	if err != nil {
+		return nil, err
+	}
+	mac := utils.Keccak256(append(append([]byte{}, derivedKey[16:32]...), cipherText...))
+
+	id, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(encryptedKeyJSON{
+		Address: strings.ToLower(strings.TrimPrefix(address, "0x")),
+		Crypto: cryptoJSON{
+			Cipher:       "aes-128-ctr",
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: cipherParamsJSON{IV: hex.EncodeToString(iv)},
+			KDF:          "scrypt",
+			KDFParams: map[string]interface{}{
+				"n":     scryptN,
+				"r":     scryptR,
+				"p":     scryptP,
+				"dklen": scryptDKLen,
+				"salt":  hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(mac),
+		},
+		ID:      id,
+		Version: 3,
+	})
+}
+
+// 키 파일 JSON을 비밀번호로 복호화해 개인키 반환
+func DecryptKey(keyJSON []byte, password string) (*ecdsa.PrivateKey, error) {
+	var encrypted encryptedKeyJSON
+	if err := json.Unmarshal(keyJSON, &encrypted); err != nil {
+		return nil, fmt.Errorf("failed to parse key file : %v", err)
+	}
+	if encrypted.Version != 3 {
+		return nil, fmt.Errorf("unsupported key file version : %d", encrypted.Version)
+	}
+	if encrypted.Crypto.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("unsupported cipher : %s", encrypted.Crypto.Cipher)
+	}
+
+	derivedKey, err := deriveKey(encrypted.Crypto, password)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherText, err := hex.DecodeString(encrypted.Crypto.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := hex.DecodeString(encrypted.Crypto.MAC)
+	if err != nil {
+		return nil, err
+	}
+	calculatedMAC := utils.Keccak256(append(append([]byte{}, derivedKey[16:32]...), cipherText...))
+	if !bytes.Equal(calculatedMAC, mac) {
+		return nil, ErrDecrypt
+	}
+
+	iv, err := hex.DecodeString(encrypted.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, err
+	}
+	plainText, err := aesCTRXOR(derivedKey[:16], cipherText, iv)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.ToECDSA(plainText)
+}
+
+// kdfparams로 비밀번호에서 키 유도 (scrypt만 지원)
+func deriveKey(c cryptoJSON, password string) ([]byte, error) {
+	if c.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported kdf : %s", c.KDF)
+	}
+
+	salt, err := hex.DecodeString(fmt.Sprint(c.KDFParams["salt"]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid kdf salt : %v", err)
+	}
+	n, okN := c.KDFParams["n"].(float64)
+	r, okR := c.KDFParams["r"].(float64)
+	p, okP := c.KDFParams["p"].(float64)
+	dkLen, okLen := c.KDFParams["dklen"].(float64)
+	if !okN || !okR || !okP || !okLen || dkLen < 32 {
+		return nil, fmt.Errorf("invalid kdf params")
+	}
+	return scrypt.Key([]byte(password), salt, int(n), int(r), int(p), int(dkLen))
+}
+
+func aesCTRXOR(key, input, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]byte, len(input))
+	cipher.NewCTR(block, iv).XORKeyStream(output, input)
+	return output, nil
+}
+
+// 키 파일 id로 쓰는 랜덤 UUID (version 4)
+func newUUID() (string, error) {
+	u := make([]byte, 16)
+	if _, err := rand.Read(u); err != nil {
+		return "", err
+	}
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16]), nil
+}
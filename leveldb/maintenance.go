@@ -0,0 +1,158 @@
+package leveldb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// 레벨 하나의 테이블 수, 크기
+type LevelStats struct {
+	Level  int   `json:"level"`
+	Tables int   `json:"tables"`
+	Size   int64 `json:"size"`
+}
+
+// DB 크기, 컴팩션 통계
+type Stats struct {
+	Path          string        `json:"path"`
+	DiskSize      int64         `json:"diskSize"` // 테이블, 로그, 매니페스트 파일 크기 합
+	Levels        []LevelStats  `json:"levels"`
+	Compactions   uint32        `json:"compactions"` // 시작 후 실행된 컴팩션 수
+	IORead        uint64        `json:"ioRead"`
+	IOWrite       uint64        `json:"ioWrite"`
+	WriteDelays   int32         `json:"writeDelays"` // 컴팩션이 밀려 쓰기가 지연된 횟수
+	WriteDelay    time.Duration `json:"writeDelay"`
+	LastCompacted time.Time     `json:"lastCompacted,omitempty"` // 마지막 전체 컴팩션 (Compact) 시각
+}
+
+var (
+	compactMu     sync.Mutex // 전체 컴팩션은 한 번에 하나만
+	statsMu       sync.Mutex // lastCompacted 보호 (컴팩션 중에도 통계 조회 가능)
+	lastCompacted time.Time
+)
+
+// DB 디렉토리의 LevelDB 파일 크기 합 (같은 디렉토리의 키 저장소, 스냅샷, 저널은 제외)
+func diskSize() (int64, error) {
+	entries, err := os.ReadDir(dbPath)
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, ".ldb") || strings.HasSuffix(name, ".log") || strings.HasPrefix(name, "MANIFEST-")) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // 컴팩션 중 삭제된 파일
+		}
+		size += info.Size()
+	}
+	return size, nil
+}
+
+// DB 크기, 레벨별 테이블, 컴팩션 통계 조회
+func GetStats() (*Stats, error) {
+	db, err := GetDBInstance()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dbinstance : %v", err)
+	}
+
+	var dbStats leveldb.DBStats
+	if err := db.Stats(&dbStats); err != nil {
+		return nil, err
+	}
+	size, err := diskSize()
+	if err != nil {
+		return nil, err
+	}
+
+	path, _ := filepath.Abs(dbPath)
+	stats := &Stats{
+		Path:        path,
+		DiskSize:    size,
+		Levels:      []LevelStats{},
+		Compactions: dbStats.MemComp + dbStats.Level0Comp + dbStats.NonLevel0Comp + dbStats.SeekComp,
+		IORead:      dbStats.IORead,
+		IOWrite:     dbStats.IOWrite,
+		WriteDelays: dbStats.WriteDelayCount,
+		WriteDelay:  dbStats.WriteDelayDuration,
+	}
+	for level, tables := range dbStats.LevelTablesCounts {
+		if tables == 0 {
+			continue
+		}
+		stats.Levels = append(stats.Levels, LevelStats{Level: level, Tables: tables, Size: dbStats.LevelSizes[level]})
+	}
+
+	statsMu.Lock()
+	stats.LastCompacted = lastCompacted
+	statsMu.Unlock()
+	return stats, nil
+}
+
+// 전체 키 범위 컴팩션 : 삭제, 덮어쓴 값이 차지하던 공간 회수, 컴팩션 전후 테이블 크기 반환
+func Compact() (int64, int64, error) {
+	db, err := GetDBInstance()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get dbinstance : %v", err)
+	}
+	if !compactMu.TryLock() {
+		return 0, 0, fmt.Errorf("compaction is already running")
+	}
+	defer compactMu.Unlock()
+
+	before, err := tableSize(db)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := db.CompactRange(util.Range{}); err != nil {
+		return before, 0, fmt.Errorf("compaction failed : %v", err)
+	}
+	after, err := tableSize(db)
+	if err != nil {
+		return before, 0, err
+	}
+	statsMu.Lock()
+	lastCompacted = time.Now()
+	statsMu.Unlock()
+	return before, after, nil
+}
+
+// 현재 버전의 테이블 크기 합 (컴팩션으로 대체된 파일은 바로 지워지지 않을 수 있어 파일 크기 대신 사용)
+func tableSize(db *leveldb.DB) (int64, error) {
+	var dbStats leveldb.DBStats
+	if err := db.Stats(&dbStats); err != nil {
+		return 0, err
+	}
+	return dbStats.LevelSizes.Sum(), nil
+}
+
+// interval마다 전체 컴팩션 실행 (0이면 실행하지 않음, LevelDB 자체 컴팩션은 항상 동작)
+func StartCompactionScheduler(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			start := time.Now()
+			before, after, err := Compact()
+			if err != nil {
+				fmt.Printf("[DB] Scheduled compaction skipped : %v\n", err)
+				continue
+			}
+			fmt.Printf("[DB] Compacted in %s, %d -> %d bytes\n", time.Since(start).Round(time.Millisecond), before, after)
+		}
+	}()
+}
@@ -1,89 +1,72 @@
 package main
 
 import (
-	"flag"
 	"fmt"
 	"os"
-
-	"simple_p2p_client/blockchain"
-	"simple_p2p_client/bootnode"
-	"simple_p2p_client/constants"
-	"simple_p2p_client/leveldb"
-	"simple_p2p_client/p2p"
-	rpcserver "simple_p2p_client/rpc-server"
-	"simple_p2p_client/utils"
+	"sort"
+	"strings"
 )
 
-func main() {
-	// 명령줄
-	mode := flag.String("mode", "fullnode", "Start in 'Bootstrap Node' or 'FullNode' ")
-	port := flag.Int("port", 30303, "The port on which the server listen (TCP & UDP)")
-	rpcPort := flag.Int("rpcport", 8080, "The port on which the RPC server listens")
-	nodeID := flag.String("nodeID", "default", "Unique node identifier")
-
-	// 명령줄 인자 파싱 (flag.Parse() 필수)
-	flag.Parse()
-
-	tcpAddress := make(chan string)
-	udpAddress := make(chan string)
-	bootstrapAddress := constants.BootstrapNodeAddress
+// CLI 명령 : 설명, 실행 함수
+type command struct {
+	usage string
+	run   func(args []string) error
+}
 
-	// nodeID 별 경로 설정
-	// nodeID 별 절대 경로 설정
-	dbPath := fmt.Sprintf("./db/%s", *nodeID)
+var commands = map[string]command{
+	"init":       {"Initialize the data directory with a genesis config file", runInitCommand},
+	"run":        {"Run a full node", runNodeCommand},
+	"bootnode":   {"Run the bootstrap node", runBootnodeCommand},
+	"dumpconfig": {"Print the effective node configuration as TOML", runDumpConfigCommand},
+	"export":     {"Export the chain to a file", runExportCommand},
+	"import":     {"Import blocks from a chain file", runImportCommand},
+	"db":         {"Database tools (inspect, stats, compact)", runDBCommand},
+	"verify":     {"Re-execute the chain from genesis and check blocks and state", runVerifyCommand},
+	"snapshot":   {"State snapshot tools (create, restore)", runSnapshotCommand},
+	"version":    {"Print version information", runVersionCommand},
+	"wallet":     {"HD wallet tools (new, derive, import)", runWalletCommand},
+	"account":    {"Keystore account tools (new, list, import)", runAccountCommand},
+	"tx":         {"Sign and send transactions (sign, send)", runTxCommand},
+	"balance":    {"Query the balance and nonce of an address", runBalanceCommand},
+}
 
-	leveldb.SetDBPath(dbPath)
+func main() {
+	args := os.Args[1:]
 
-	// DB 초기화
-	if err := leveldb.InitDB(); err != nil {
-		fmt.Printf("Failed to initialize DB for node %s: %v\n", *nodeID, err)
-		os.Exit(1)
+	// 서브커맨드 없이 플래그만 주면 예전처럼 노드 실행 (run과 같음)
+	name := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
-	defer func() {
-		if err := leveldb.CleanupDB(); err != nil {
-			fmt.Printf("Failed to cleanup DB: %v\n", err)
-		}
-	}()
-
-	if *mode == "bootnode" {
-		bootnode.StartBootstrapServer()
 
-	} else if *mode == "fullnode" {
-		// FullNode 초기화 로직
-		initializeFullNode(*port, *rpcPort, bootstrapAddress, tcpAddress, udpAddress)
-	} else {
-		fmt.Println("Invalid mode. Use -mode=bootstrap or -mode=fullNode")
+	if name == "help" {
+		printUsage()
+		return
 	}
-
-}
-
-func initializeFullNode(port, rpcPort int, bootstrapAddress string, tcpAddress, udpAddress chan string) {
-	// 노드 계정 초기화
-	if err := blockchain.InitializeNodeAccount(); err != nil {
-		fmt.Printf("Failed to initialize node account: %v\n", err)
-		os.Exit(1)
+	cmd, exists := commands[name]
+	if !exists {
+		fmt.Printf("Unknown command %q\n\n", name)
+		printUsage()
+		os.Exit(2)
 	}
 
-	// Blockchain 초기화
-	if err := blockchain.InitGenesisBlock(); err != nil {
-		fmt.Printf("Failed to initialize blockchain: %v\n", err)
+	if err := cmd.run(args); err != nil {
+		fmt.Println(err)
 		os.Exit(1)
 	}
+}
 
-	blockchain.InitMempool()
-	go rpcserver.StartRpcServer(rpcPort)
-	go p2p.StartTCPServer(tcpAddress, port)
-	go p2p.StartUDPServer(udpAddress, tcpAddress, port)
+func printUsage() {
+	fmt.Println("Usage: simple_p2p_client <command> [flags]")
+	fmt.Println("\nCommands:")
 
-	udpServerAddress := <-udpAddress
-	nodeAddress, err := p2p.ConnectBootstrapNode(bootstrapAddress, udpServerAddress)
-	if err != nil {
-		utils.PrintError(fmt.Sprintf("Failed to connect to bootstrap node: %v", err))
-		return
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
 	}
-
-	fmt.Println("[Node Discovery] Peer addresses from bootnode:", nodeAddress)
-	go blockchain.StartBlockchainProcessor()
-	go blockchain.StartBlockCreator()
-	p2p.StartClient(nodeAddress)
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %-9s %s\n", name, commands[name].usage)
+	}
+	fmt.Println("\nRun 'simple_p2p_client <command> -h' for the flags of a command.")
 }
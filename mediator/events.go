@@ -0,0 +1,80 @@
+package mediator
+
+// 이벤트 타입
+type EventType int
+
+const (
+	NewTxEventType          EventType = iota // p2p, rpc => blockchain : 검증 전 트랜잭션
+	NewBlockEventType                        // p2p => blockchain : 피어로부터 받은 블록
+	ChainHeadEventType                       // blockchain => p2p, rpc : 새 블록이 체인에 추가됨
+	TxValidationResultType                   // blockchain => p2p, rpc : 트랜잭션 검증 결과
+	PeerEventType                            // p2p => 누구나 : 피어 연결, 해제
+	AccountChangedEventType                  // blockchain => rpc : 블록 실행으로 계정 잔액, 논스 변경
+	NewPendingTxsEventType                   // blockchain => rpc : 멤풀 pending에 들어간 트랜잭션
+)
+
+// 이벤트 버스로 주고받는 모든 이벤트
+type Event interface {
+	Type() EventType
+}
+
+// 트랜잭션, 블록이 어디서 들어왔는지
+type Source int
+
+const (
+	SourceP2P Source = iota
+	SourceRPC
+)
+
+// 검증 전 트랜잭션 (RawTransaction JSON)
+type NewTxEvent struct {
+	Payload string
+	Source  Source
+}
+
+// 피어로부터 받은 블록 (Block JSON)
+type NewBlockEvent struct {
+	Payload string
+}
+
+// 체인에 새 블록이 저장, 실행됨 (Block JSON 포함)
+type ChainHeadEvent struct {
+	Number  uint64
+	Hash    string
+	Payload string
+}
+
+// 트랜잭션 검증 결과, 성공 시 Payload는 전파할 RawTransaction JSON
+type TxValidationResult struct {
+	Source  Source
+	TxHash  string
+	Payload string
+	Err     error
+}
+
+// 피어 연결 상태 변경
+type PeerEvent struct {
+	Address   string
+	Connected bool
+}
+
+// 블록 실행 후 변경된 계정 상태 (Balance는 10진수 문자열)
+type AccountChangedEvent struct {
+	Address     string
+	Balance     string
+	Nonce       uint64
+	BlockNumber uint64
+}
+
+// 멤풀 pending에 새로 들어간 트랜잭션 해시 (검증 후 바로 들어가거나 future에서 승격, future에만 들어간 트랜잭션은 제외)
+type NewPendingTxsEvent struct {
+	Hashes []string
+}
+
+func (NewTxEvent) Type() EventType          { return NewTxEventType }
+func (NewBlockEvent) Type() EventType       { return NewBlockEventType }
+func (ChainHeadEvent) Type() EventType      { return ChainHeadEventType }
+func (TxValidationResult) Type() EventType  { return TxValidationResultType }
+func (PeerEvent) Type() EventType           { return PeerEventType }
+func (AccountChangedEvent) Type() EventType { return AccountChangedEventType }
+func (NewPendingTxsEvent) Type() EventType  { return NewPendingTxsEventType }
@@ -1,13 +1,35 @@
 package mediator
 
-import "sync"
+import (
+	"sync"
+	"sync/atomic"
+)
 
+// 패키지(p2p, blockchain, rpc) 간 이벤트를 중재하는 타입 기반 pub/sub 이벤트 버스
 type Mediator struct {
-	RPCToBlockchain chan string // rpc => blockchain
-	BlockchainToRPC chan string // blockchain => rpc
+	mu            sync.RWMutex
+	subscriptions map[uint64]*Subscription
+	nextSubID     uint64
+}
+
+// 구독자의 버퍼가 가득 찼을 때의 처리 정책
+type Policy int
 
-	P2PToBlockchain chan string // p2p => blockchain
-	BlockchainToP2P chan string // blockchain => p2p
+const (
+	PolicyDrop  Policy = iota // 버퍼가 가득 차면 이벤트를 버림 (발행자는 막히지 않음)
+	PolicyBlock               // 구독자가 이벤트를 꺼낼 때까지 발행자가 대기
+)
+
+// 하나의 구독 : 구독한 이벤트 타입들만 Events() 채널로 전달됨
+type Subscription struct {
+	id       uint64
+	types    map[EventType]bool
+	policy   Policy
+	events   chan Event
+	done     chan struct{}
+	once     sync.Once
+	dropped  uint64
+	mediator *Mediator
 }
 
 var instance *Mediator
@@ -16,12 +38,89 @@ var once sync.Once
 // GetMediatorInstance
 func GetMediatorInstance() *Mediator {
 	once.Do(func() {
-		instance = &Mediator{
-			P2PToBlockchain: make(chan string, 100),
-			BlockchainToP2P: make(chan string, 100),
-			RPCToBlockchain: make(chan string, 100),
-			BlockchainToRPC: make(chan string, 100),
-		}
+		instance = NewMediator()
 	})
 	return instance
 }
+
+// 새 이벤트 버스 생성 (테스트 등 싱글톤이 아닌 인스턴스가 필요할 때)
+func NewMediator() *Mediator {
+	return &Mediator{
+		subscriptions: make(map[uint64]*Subscription),
+	}
+}
+
+// 이벤트 타입들을 구독, buffer 크기와 backpressure 정책 지정
+func (m *Mediator) Subscribe(buffer int, policy Policy, types ...EventType) *Subscription {
+	sub := &Subscription{
+		types:    make(map[EventType]bool),
+		policy:   policy,
+		events:   make(chan Event, buffer),
+		done:     make(chan struct{}),
+		mediator: m,
+	}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	m.mu.Lock()
+	m.nextSubID++
+	sub.id = m.nextSubID
+	m.subscriptions[sub.id] = sub
+	m.mu.Unlock()
+
+	return sub
+}
+
+// 이벤트를 해당 타입의 모든 구독자에게 전달
+func (m *Mediator) Publish(event Event) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, sub := range m.subscriptions {
+		if !sub.types[event.Type()] {
+			continue
+		}
+		sub.deliver(event)
+	}
+}
+
+// 정책에 따라 구독자 채널로 이벤트 전달
+func (s *Subscription) deliver(event Event) {
+	switch s.policy {
+	case PolicyBlock:
+		select {
+		case s.events <- event:
+		case <-s.done: // 구독 해지 중이면 포기
+		}
+	default:
+		select {
+		case s.events <- event:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	}
+}
+
+// 구독한 이벤트를 받는 채널, 구독 해지 시 닫힘
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// PolicyDrop으로 버려진 이벤트 수
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// 구독 해지 : 대기 중인 발행자를 풀어준 뒤 목록에서 제거하고 채널을 닫음
+func (s *Subscription) Unsubscribe() {
+	s.once.Do(func() {
+		close(s.done)
+
+		s.mediator.mu.Lock()
+		delete(s.mediator.subscriptions, s.id)
+		s.mediator.mu.Unlock()
+
+		close(s.events)
+	})
+}
@@ -0,0 +1,73 @@
+package mediator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishToSubscribedTypesOnly(t *testing.T) {
+	m := NewMediator()
+
+	txSub := m.Subscribe(10, PolicyDrop, NewTxEventType)
+	defer txSub.Unsubscribe()
+	headSub := m.Subscribe(10, PolicyDrop, ChainHeadEventType)
+	defer headSub.Unsubscribe()
+
+	m.Publish(NewTxEvent{Payload: "tx", Source: SourceRPC})
+
+	select {
+	case event := <-txSub.Events():
+		ev, ok := event.(NewTxEvent)
+		if !ok || ev.Payload != "tx" {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("tx subscriber did not receive event")
+	}
+
+	select {
+	case event := <-headSub.Events():
+		t.Errorf("head subscriber should not receive tx event, got %+v", event)
+	default:
+	}
+}
+
+func TestDropPolicy(t *testing.T) {
+	m := NewMediator()
+	sub := m.Subscribe(1, PolicyDrop, PeerEventType)
+	defer sub.Unsubscribe()
+
+	// 버퍼 1개, 나머지 2개는 버려짐
+	for i := 0; i < 3; i++ {
+		m.Publish(PeerEvent{Address: "127.0.0.1:30303", Connected: true})
+	}
+
+	if sub.Dropped() != 2 {
+		t.Errorf("expected 2 dropped events, got %d", sub.Dropped())
+	}
+}
+
+func TestUnsubscribeReleasesBlockedPublisher(t *testing.T) {
+	m := NewMediator()
+	sub := m.Subscribe(0, PolicyBlock, ChainHeadEventType)
+
+	published := make(chan struct{})
+	go func() {
+		m.Publish(ChainHeadEvent{Number: 2})
+		close(published)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	sub.Unsubscribe()
+
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("publisher still blocked after unsubscribe")
+	}
+
+	// 해지 후 채널은 닫혀 있어야 함
+	if _, ok := <-sub.Events(); ok {
+		t.Error("expected events channel to be closed")
+	}
+}
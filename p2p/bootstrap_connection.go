@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"simple_p2p_client/constants"
 	pc "simple_p2p_client/protocol_constants"
 	"simple_p2p_client/utils"
 	"strings"
@@ -16,6 +17,24 @@ import (
 // 글로벌 변수 : 모든 피어의 연결 정보 저장하는 리스트
 var ConnectedPeers []net.Conn
 
+// 최대 피어 수 (0이면 제한 없음)
+var maxPeers = constants.DefaultMaxPeers
+
+// 최대 피어 수 설정 (노드 시작 시 설정으로 지정)
+func SetMaxPeers(n int) {
+	maxPeers = n
+}
+
+// 현재 연결된 피어 수
+func PeerCount() int {
+	return len(ConnectedPeers)
+}
+
+// 최대 피어 수에 도달했는지
+func peersFull() bool {
+	return maxPeers > 0 && PeerCount() >= maxPeers
+}
+
 // Bootstrap : UDP version
 func ConnectBootstrapNode(bootstrapAddress string, udpServerAddress string) ([]string, error) {
 	var nodeLists []string
@@ -28,6 +28,10 @@ func StartClient(nodeAddress []string) {
 				fmt.Println("Empty node address, skipping...")
 				continue
 			}
+			if peersFull() {
+				fmt.Printf("[Node Discovery] Reached max peers (%d), skipping remaining nodes\n", maxPeers)
+				break
+			}
 
 			nodeUDPAddr, err := net.ResolveUDPAddr("udp", address)
 			if err != nil {
@@ -81,7 +85,8 @@ func StartClient(nodeAddress []string) {
 					fmt.Printf("[Node Discovery] Received 'ENRResponse' from node : %v\n", nodeUDPAddr)
 					fmt.Printf("[Node Discovery] TCP server : %v\n", nodeUDPAddr)
 
-					tcpServer := string(buffer[1:n])
+					// 피어가 모든 인터페이스에서 리스닝하면 UDP로 응답한 IP로 연결
+					tcpServer := ResolveAdvertisedAddress(string(buffer[1:n]), nodeUDPAddr.IP)
 					fmt.Printf("[Node Discovery] TCP server : %v, dialing...\n", tcpServer)
 
 					// 5. TCP 연결
@@ -92,6 +97,7 @@ func StartClient(nodeAddress []string) {
 					} else {
 						fmt.Printf("[Node Discovery] Successfully connected to node,  %v\n", tcpServer)
 						ConnectedPeers = append(ConnectedPeers, conn)
+						mediator.GetMediatorInstance().Publish(mediator.PeerEvent{Address: tcpServer, Connected: true})
 					}
 
 				}
@@ -125,21 +131,24 @@ func StartClient(nodeAddress []string) {
 		}
 	}()
 
-	// BlockchainToP2P 채널에서 메시지를 읽어와 피어들에게 전송
+	// 검증된 트랜잭션, 새 블록 이벤트를 구독해 피어들에게 전송
+	sub := mediator.GetMediatorInstance().Subscribe(100, mediator.PolicyBlock, mediator.TxValidationResultType, mediator.ChainHeadEventType)
 	go func() {
-		for processedMessage := range mediator.GetMediatorInstance().BlockchainToP2P {
-
-			if len(processedMessage) == 0 {
-				utils.PrintError("Received empty message, skipping...")
-				continue
-			}
+		for event := range sub.Events() {
 
-			// 첫 바이트 (프로토콜 ID)와 나머지 메시지 분리
-			protocolID := processedMessage[0]
-			messageContent := processedMessage[1:]
+			switch ev := event.(type) {
+			case mediator.TxValidationResult:
+				// 검증 실패한 트랜잭션은 전파하지 않음
+				if ev.Err != nil {
+					continue
+				}
+				utils.PrintMessage(fmt.Sprintf("[P2P] Forwarding transaction to peers: %s", ev.TxHash))
+				HandleSendingMessages(ConnectedPeers, pc.P2PTransactionMessage, ev.Payload)
 
-			utils.PrintMessage(fmt.Sprintf("[P2P] Forwarding message to peers: %s", processedMessage))
-			HandleSendingMessages(ConnectedPeers, protocolID, messageContent)
+			case mediator.ChainHeadEvent:
+				utils.PrintMessage(fmt.Sprintf("[P2P] Forwarding block to peers: %s", ev.Hash))
+				HandleSendingMessages(ConnectedPeers, pc.P2PBlockMessage, ev.Payload)
+			}
 		}
 	}()
 
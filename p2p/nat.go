@@ -0,0 +1,63 @@
+package p2p
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// 다른 노드에 알릴 외부 IP (-nat extip:<ip>), nil이면 리스닝 주소를 그대로 알림
+var externalIP net.IP
+
+// NAT 설정 파싱 : "" 또는 "none"이면 nil, "extip:<ip>"면 해당 IP (IPv4, IPv6)
+func ParseNAT(spec string) (net.IP, error) {
+	switch {
+	case spec == "" || spec == "none":
+		return nil, nil
+	case strings.HasPrefix(spec, "extip:"):
+		ip := net.ParseIP(strings.Trim(strings.TrimPrefix(spec, "extip:"), "[]"))
+		if ip == nil || ip.IsUnspecified() {
+			return nil, fmt.Errorf("invalid external IP in %q", spec)
+		}
+		return ip, nil
+	default:
+		return nil, fmt.Errorf("unsupported nat %q, use none or extip:<ip>", spec)
+	}
+}
+
+// 외부 IP 설정 (노드 시작 시, 서버 실행 전에 호출)
+func SetExternalIP(ip net.IP) {
+	externalIP = ip
+}
+
+// 리스닝 주소를 다른 노드에 알릴 주소로 변환 (외부 IP가 있으면 호스트를 교체)
+func AdvertisedAddress(listenAddress string) string {
+	if externalIP == nil {
+		return listenAddress
+	}
+	_, port, err := net.SplitHostPort(listenAddress)
+	if err != nil {
+		return listenAddress
+	}
+	return net.JoinHostPort(externalIP.String(), port)
+}
+
+// 받은 주소를 실제로 연결할 수 있는 주소로 변환
+// 호스트가 비어 있거나 0.0.0.0, :: 이면 (모든 인터페이스에서 리스닝, 외부 IP 미지정)
+// 또는 루프백인데 메시지가 다른 호스트에서 왔으면 메시지를 보낸 IP로 교체
+func ResolveAdvertisedAddress(advertised string, observed net.IP) string {
+	host, port, err := net.SplitHostPort(advertised)
+	if err != nil || observed == nil {
+		return advertised
+	}
+	if host != "" {
+		ip := net.ParseIP(host)
+		if ip == nil {
+			return advertised // 호스트 이름은 그대로
+		}
+		if !ip.IsUnspecified() && !(ip.IsLoopback() && !observed.IsLoopback()) {
+			return advertised
+		}
+	}
+	return net.JoinHostPort(observed.String(), port)
+}
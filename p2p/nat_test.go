@@ -0,0 +1,57 @@
+package p2p
+
+import (
+	"net"
+	"testing"
+)
+
+func TestParseNAT(t *testing.T) {
+	if ip, err := ParseNAT("none"); err != nil || ip != nil {
+		t.Errorf("expected no external IP for none, got %v, %v", ip, err)
+	}
+	if ip, err := ParseNAT("extip:2001:db8::1"); err != nil || !ip.Equal(net.ParseIP("2001:db8::1")) {
+		t.Errorf("failed to parse IPv6 extip : %v, %v", ip, err)
+	}
+	for _, spec := range []string{"extip:", "extip:0.0.0.0", "upnp", "extip:host"} {
+		if _, err := ParseNAT(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestAdvertisedAddress(t *testing.T) {
+	defer SetExternalIP(nil)
+
+	if got := AdvertisedAddress("0.0.0.0:30303"); got != "0.0.0.0:30303" {
+		t.Errorf("expected listen address without external IP, got %s", got)
+	}
+	SetExternalIP(net.ParseIP("203.0.113.7"))
+	if got := AdvertisedAddress("[::]:30303"); got != "203.0.113.7:30303" {
+		t.Errorf("expected external IP with listen port, got %s", got)
+	}
+	SetExternalIP(net.ParseIP("2001:db8::1"))
+	if got := AdvertisedAddress("0.0.0.0:30303"); got != "[2001:db8::1]:30303" {
+		t.Errorf("expected bracketed IPv6 address, got %s", got)
+	}
+}
+
+func TestResolveAdvertisedAddress(t *testing.T) {
+	remote := net.ParseIP("172.17.0.3")
+	tests := []struct {
+		advertised string
+		observed   net.IP
+		expected   string
+	}{
+		{"0.0.0.0:30303", remote, "172.17.0.3:30303"},
+		{"[::]:30303", net.ParseIP("fd00::3"), "[fd00::3]:30303"},
+		{"127.0.0.1:30303", remote, "172.17.0.3:30303"},
+		{"127.0.0.1:30303", net.ParseIP("127.0.0.1"), "127.0.0.1:30303"},
+		{"203.0.113.7:30303", remote, "203.0.113.7:30303"},
+		{"node1.local:30303", remote, "node1.local:30303"},
+	}
+	for _, tt := range tests {
+		if got := ResolveAdvertisedAddress(tt.advertised, tt.observed); got != tt.expected {
+			t.Errorf("ResolveAdvertisedAddress(%s, %s) = %s, expected %s", tt.advertised, tt.observed, got, tt.expected)
+		}
+	}
+}
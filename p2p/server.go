@@ -9,19 +9,13 @@ import (
 	pc "simple_p2p_client/protocol_constants"
 	"simple_p2p_client/utils"
 
-	"strconv"
 	"strings"
 )
 
-// TCP 서버 실행
-func StartTCPServer(tcpAddress chan<- string, port int) {
+// TCP 서버 실행 (address : host:port)
+func StartTCPServer(tcpAddress chan<- string, address string) {
 
 	// TCP 서버 시작
-	address := "127.0.0.1:" + strconv.Itoa(port)
-	// min := 6666
-	// max := 9999
-	// randomPort := rand.Intn(max-min+1) + min
-
 	listener, err := net.Listen("tcp", address)
 	if err != nil {
 		fmt.Println("Error starting server:", err)
@@ -29,8 +23,10 @@ func StartTCPServer(tcpAddress chan<- string, port int) {
 		return
 	}
 
-	fmt.Println("[P2P] TCP Server is listening on :", address)
-	tcpAddress <- address
+	// ENRResponse로 알릴 주소 (외부 IP가 설정되어 있으면 외부 IP)
+	advertised := AdvertisedAddress(listener.Addr().String())
+	fmt.Println("[P2P] TCP Server is listening on :", listener.Addr().String(), ", advertised :", advertised)
+	tcpAddress <- advertised
 
 	defer listener.Close()
 
@@ -40,35 +36,44 @@ func StartTCPServer(tcpAddress chan<- string, port int) {
 			utils.PrintError(fmt.Sprintf("Error accepting connection: %v", err))
 			continue
 		}
+		if peersFull() {
+			utils.PrintError(fmt.Sprintf("[P2P] Too many peers (max %d), rejecting %v", maxPeers, conn.RemoteAddr().String()))
+			conn.Close()
+			continue
+		}
 		utils.PrintMessage(fmt.Sprintf("[P2P] Peer connected from: %v", conn.RemoteAddr().String()))
 
 		// 연결된 피어를 글로벌 변수에 저장
 		ConnectedPeers = append(ConnectedPeers, conn)
+		mediator.GetMediatorInstance().Publish(mediator.PeerEvent{Address: conn.RemoteAddr().String(), Connected: true})
 
 		// 피어와 통신 처리
 		go RefactorHandleIncomingMessages(conn)
 	}
 }
 
-// UDP 서버 실행
-func StartUDPServer(udpAddress chan<- string, tcpAddress <-chan string, port int) {
+// UDP 서버 실행 (address : host:port, TCP 서버와 같은 주소)
+func StartUDPServer(udpAddress chan<- string, tcpAddress <-chan string, address string) {
 
 	// 1. Create UDP address
-	addr := net.UDPAddr{
-		Port: port,
-		IP:   net.ParseIP("127.0.0.1"),
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		fmt.Println("Error resolving UDP address :", err)
+		return
 	}
 
 	// 2. Waiting for UDP connection
-	conn, err := net.ListenUDP("udp", &addr)
+	conn, err := net.ListenUDP("udp", addr)
 	if err != nil {
 		fmt.Println("Error starting UDP server :", err)
 		return
 	}
 
-	fmt.Println("[P2P] UDP Server is listening on : ", fmt.Sprintf("%s:%d", addr.IP.String(), addr.Port))
+	// FindNode로 부트노드에 알릴 주소 (외부 IP가 설정되어 있으면 외부 IP)
+	advertised := AdvertisedAddress(conn.LocalAddr().String())
+	fmt.Println("[P2P] UDP Server is listening on : ", conn.LocalAddr().String(), ", advertised :", advertised)
 
-	udpAddress <- addr.String()
+	udpAddress <- advertised
 
 	defer conn.Close()
 
@@ -118,6 +123,7 @@ func StartUDPServer(udpAddress chan<- string, tcpAddress <-chan string, port int
 	}
 }
 
+// 피어로부터 받은 메시지를 첫 바이트(프로토콜 ID)로 구분해 이벤트 발행
 func RefactorHandleIncomingMessages(conn net.Conn) {
 	defer conn.Close()
 
@@ -128,6 +134,7 @@ func RefactorHandleIncomingMessages(conn net.Conn) {
 			if err == io.EOF {
 				utils.PrintError(fmt.Sprintf("Peer disconnected: %s", conn.RemoteAddr().String()))
 				ConnectedPeers = utils.RemoveConn(ConnectedPeers, conn)
+				mediatorInstance.Publish(mediator.PeerEvent{Address: conn.RemoteAddr().String(), Connected: false})
 
 			} else {
 				utils.PrintError(fmt.Sprintf("Error reading from peer: %v", err))
@@ -136,8 +143,22 @@ func RefactorHandleIncomingMessages(conn net.Conn) {
 		}
 		// 메시지 처리
 		message = strings.TrimSpace(message)
+		if len(message) == 0 {
+			fmt.Println("Received empty message, skipping...")
+			continue
+		}
+
+		messageType := message[0]
+		messageContent := message[1:] // 프로토콜 ID 제거
 
-		// p2p => blockchain 메시지 전달
-		mediatorInstance.P2PToBlockchain <- message
+		// p2p => blockchain 이벤트 발행
+		switch messageType {
+		case pc.P2PTransactionMessage:
+			mediatorInstance.Publish(mediator.NewTxEvent{Payload: messageContent, Source: mediator.SourceP2P})
+		case pc.P2PBlockMessage:
+			mediatorInstance.Publish(mediator.NewBlockEvent{Payload: messageContent})
+		default: // 채팅
+			utils.PrintMessage(fmt.Sprintf("[MESSAGE] Recevied from peer : %s", messageContent))
+		}
 	}
 }
@@ -4,18 +4,22 @@ import (
 	"fmt"
 	"net/http"
 	"simple_p2p_client/account"
+	"simple_p2p_client/blockchain"
+	"strconv"
 )
 
 type AccountAPI struct{}
 
 type GetAccountArgs struct {
 	Address string `json:"address"`
+	Block   string `json:"block,omitempty"` // 블록 번호(10진수) 또는 해시, 비어 있거나 "latest"면 현재 상태
 }
 
 type GetAccountReply struct {
-	Address string `json:"address"`
-	Balance string `json:"balance"`
-	Nonce   uint64 `json:"nonce"`
+	Address     string `json:"address"`
+	Balance     string `json:"balance"`
+	Nonce       uint64 `json:"nonce"`
+	BlockNumber uint64 `json:"blockNumber,omitempty"` // 과거 상태를 조회한 경우 블록 번호
 }
 
 // 주소 조회
@@ -25,8 +29,18 @@ func (s *AccountAPI) GetAccountInfo(r *http.Request, args *GetAccountArgs, reply
 		return fmt.Errorf("invalid address format: %s", args.Address)
 	}
 
-	// 계정 정보 조회
-	accountData, err := account.GetAccount(args.Address)
+	// 계정 정보 조회 (블록을 지정하면 해당 블록 실행 후 상태)
+	var accountData *account.Account
+	var err error
+	if args.Block == "" || args.Block == "latest" {
+		accountData, err = account.GetAccount(args.Address)
+	} else {
+		reply.BlockNumber, err = resolveBlockArg(args.Block)
+		if err != nil {
+			return err
+		}
+		accountData, err = blockchain.GetAccountAt(args.Address, reply.BlockNumber)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to retrieve account info: %v", err)
 	}
@@ -39,25 +53,79 @@ func (s *AccountAPI) GetAccountInfo(r *http.Request, args *GetAccountArgs, reply
 	return nil
 }
 
-type NewAccountArgs struct {
+// 블록 번호(10진수) 또는 블록 해시를 블록 번호로 변환
+func resolveBlockArg(block string) (uint64, error) {
+	if number, err := strconv.ParseUint(block, 10, 64); err == nil {
+		return number, nil
+	}
+	blockData, err := blockchain.GetBlockByHash(block)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block %q : %v", block, err)
+	}
+	return blockData.Number, nil
+}
+
+type GetPendingNonceReply struct {
+	Address   string `json:"address"`
+	Nonce     uint64 `json:"nonce"`     // 멤풀 pending을 모두 실행했을 때의 nonce
+	NextNonce uint64 `json:"nextNonce"` // 다음 트랜잭션에 사용할 nonce
+	Balance   string `json:"balance"`   // 멤풀 pending을 모두 실행했을 때의 잔액
+}
+
+// 멤풀 pending 트랜잭션까지 반영한 nonce 조회
+func (s *AccountAPI) GetPendingNonce(r *http.Request, args *GetAccountArgs, reply *GetPendingNonceReply) error {
+	if !account.IsValidAddress(args.Address) {
+		return fmt.Errorf("invalid address format: %s", args.Address)
+	}
+
+	state, err := blockchain.GetPendingAccountState(args.Address)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve pending state: %v", err)
+	}
+
+	reply.Address = args.Address
+	reply.Nonce = state.Nonce
+	reply.NextNonce = state.Nonce + 1
+	reply.Balance = state.Balance.String()
+	return nil
+}
+
+type GetTransactionsArgs struct {
+	Address   string `json:"address"`
+	FromBlock uint64 `json:"fromBlock"` // 0이면 처음부터
+	ToBlock   uint64 `json:"toBlock"`   // 0이면 마지막 블록까지
+	Limit     int    `json:"limit"`     // 0이면 50, 최대 1000
+	Cursor    string `json:"cursor"`    // 이전 응답의 nextCursor
 }
 
-type NewAccountReply struct {
-	PrivateKey string
-	Address    string
+type GetTransactionsReply struct {
+	Address      string                 `json:"address"`
+	Transactions []blockchain.AddressTx `json:"transactions"`
+	NextCursor   string                 `json:"nextCursor,omitempty"`   // 다음 페이지가 없으면 생략
+	PrunedBefore uint64                 `json:"prunedBefore,omitempty"` // 이 블록 이전은 본문을 정리해 조회할 수 없음 (정리하지 않았으면 생략)
 }
 
-// 랜덤으로 새 계정 생성 (개인키, 주소 반환)
-func (s *AccountAPI) NewAccount(r *http.Request, args *NewAccountArgs, reply *NewAccountReply) error {
+// 주소가 보내거나 받은 트랜잭션 조회 (최신 순, 페이지 단위)
+func (s *AccountAPI) GetTransactions(r *http.Request, args *GetTransactionsArgs, reply *GetTransactionsReply) error {
+	if !account.IsValidAddress(args.Address) {
+		return fmt.Errorf("invalid address format: %s", args.Address)
+	}
+
+	transactions, nextCursor, err := blockchain.GetAddressTransactions(args.Address, args.FromBlock, args.ToBlock, args.Limit, args.Cursor)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve transactions: %v", err)
+	}
 
-	// 랜덤 계정 생성
-	privateKey, address, err := account.CreateAccount()
+	tail, err := blockchain.GetPruneTail()
 	if err != nil {
-		return fmt.Errorf("making new account error : %v", err)
+		return fmt.Errorf("failed to retrieve prune tail: %v", err)
 	}
 
-	// 주소, 개인키 반환
-	reply.Address = address
-	reply.PrivateKey = privateKey
+	reply.Address = args.Address
+	reply.Transactions = transactions
+	reply.NextCursor = nextCursor
+	if tail > 1 {
+		reply.PrunedBefore = tail
+	}
 	return nil
 }
@@ -0,0 +1,38 @@
+package rpcserver
+
+import (
+	"fmt"
+	"net/http"
+	"simple_p2p_client/blockchain"
+)
+
+type ChainAPI struct{}
+
+type GetSupplyArgs struct{}
+
+type GetSupplyReply struct {
+	TotalSupply    string `json:"totalSupply"`    // 총 발행량 (초기 발행 + 블록 보상 - 소각된 수수료)
+	BlockNumber    uint64 `json:"blockNumber"`    // 기준 블록 번호
+	NextReward     string `json:"nextReward"`     // 다음 블록 보상
+	FeeBurnPercent uint64 `json:"feeBurnPercent"` // 수수료 소각 비율(%)
+}
+
+// 총 발행량과 보상 정책 조회
+func (c *ChainAPI) GetSupply(r *http.Request, args *GetSupplyArgs, reply *GetSupplyReply) error {
+	latest, err := blockchain.GetLatestBlock()
+	if err != nil {
+		return fmt.Errorf("failed to get latest block: %v", err)
+	}
+
+	supply, err := blockchain.GetTotalSupply()
+	if err != nil {
+		return fmt.Errorf("failed to get total supply: %v", err)
+	}
+
+	schedule := blockchain.ChainConfig().Reward
+	reply.TotalSupply = supply.String()
+	reply.BlockNumber = latest.Number
+	reply.NextReward = schedule.BlockReward(latest.Number + 1).String()
+	reply.FeeBurnPercent = schedule.FeeBurnPercent
+	return nil
+}
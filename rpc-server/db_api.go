@@ -0,0 +1,71 @@
+package rpcserver
+
+import (
+	"fmt"
+	"net/http"
+	"simple_p2p_client/blockchain"
+	"simple_p2p_client/leveldb"
+	"time"
+)
+
+type DBAPI struct{}
+
+type DBStatsArgs struct{}
+
+type DBStatsReply struct {
+	leveldb.Stats
+	HeadBlock   uint64 `json:"headBlock"`
+	OldestBody  uint64 `json:"oldestBody"`  // 트랜잭션이 남아 있는 가장 오래된 블록
+	OldestState uint64 `json:"oldestState"` // 과거 상태를 조회할 수 있는 가장 오래된 블록
+}
+
+// DB 크기, 레벨별 테이블, 컴팩션 통계와 보관 범위 조회
+func (d *DBAPI) Stats(r *http.Request, args *DBStatsArgs, reply *DBStatsReply) error {
+	stats, err := leveldb.GetStats()
+	if err != nil {
+		return fmt.Errorf("failed to get db stats: %v", err)
+	}
+	latest, err := blockchain.GetLatestBlock()
+	if err != nil {
+		return fmt.Errorf("failed to get latest block: %v", err)
+	}
+	oldestBody, err := blockchain.GetPruneTail()
+	if err != nil {
+		return err
+	}
+	oldestState, err := blockchain.GetOldestStateBlock()
+	if err != nil {
+		return err
+	}
+
+	reply.Stats = *stats
+	reply.HeadBlock = latest.Number
+	reply.OldestBody = oldestBody
+	reply.OldestState = oldestState
+	return nil
+}
+
+type DBCompactArgs struct{}
+
+type DBCompactReply struct {
+	SizeBefore int64  `json:"sizeBefore"`
+	SizeAfter  int64  `json:"sizeAfter"`
+	Duration   string `json:"duration"`
+}
+
+// 관리자 전용 : 전체 컴팩션 실행 (끝날 때까지 응답하지 않음, 노드와 같은 머신에서만 호출 가능)
+func (d *DBAPI) Compact(r *http.Request, args *DBCompactArgs, reply *DBCompactReply) error {
+	if !isLocalRequest(r) {
+		return fmt.Errorf("db.Compact is only available from localhost")
+	}
+	start := time.Now()
+	before, after, err := leveldb.Compact()
+	if err != nil {
+		return err
+	}
+	reply.SizeBefore = before
+	reply.SizeAfter = after
+	reply.Duration = time.Since(start).Round(time.Millisecond).String()
+	fmt.Printf("[RPC] Compacted database, %d -> %d bytes\n", before, after)
+	return nil
+}
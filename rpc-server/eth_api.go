@@ -0,0 +1,418 @@
+package rpcserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"runtime"
+	"simple_p2p_client/account"
+	"simple_p2p_client/blockchain"
+	"simple_p2p_client/constants"
+	"simple_p2p_client/p2p"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	db "github.com/syndtr/goleveldb/leveldb"
+)
+
+// JSON-RPC 2.0 표준 에러 코드
+const (
+	ethErrParse          = -32700
+	ethErrInvalidRequest = -32600
+	ethErrMethodNotFound = -32601
+	ethErrInvalidParams  = -32602
+	ethErrInternal       = -32603
+	ethErrServer         = -32000 // 트랜잭션 거절 등 서버 정의 에러
+)
+
+// 요청 본문 최대 크기
+const ethMaxRequestSize = 5 * 1024 * 1024
+
+// JSON-RPC 2.0 요청
+type ethRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// JSON-RPC 2.0 에러 객체
+type ethError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *ethError) Error() string {
+	return e.Message
+}
+
+// 각 메서드는 positional params를 받아 결과를 반환
+type ethMethod func(params []json.RawMessage) (interface{}, error)
+
+// eth_, net_, web3_ 네임스페이스를 처리하는 JSON-RPC 2.0 핸들러
+type EthHandler struct {
+	methods map[string]ethMethod
+}
+
+func NewEthHandler() *EthHandler {
+	h := &EthHandler{}
+	h.methods = map[string]ethMethod{
+		"eth_blockNumber":          h.blockNumber,
+		"eth_getBalance":           h.getBalance,
+		"eth_getTransactionCount":  h.getTransactionCount,
+		"eth_getBlockByNumber":     h.getBlockByNumber,
+		"eth_getBlockByHash":       h.getBlockByHash,
+		"eth_getTransactionByHash": h.getTransactionByHash,
+		"eth_sendRawTransaction":   h.sendRawTransaction,
+		"eth_chainId":              h.chainID,
+		"net_version":              h.netVersion,
+		"net_peerCount":            h.peerCount,
+		"web3_clientVersion":       h.clientVersion,
+	}
+	return h
+}
+
+func (h *EthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "JSON-RPC requests must use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, ethMaxRequestSize))
+	if err != nil {
+		writeEthJSON(w, ethErrorResponse(nil, &ethError{Code: ethErrParse, Message: "failed to read request body"}))
+		return
+	}
+
+	// 배치 요청인지 확인
+	trimmed := strings.TrimSpace(string(body))
+	if strings.HasPrefix(trimmed, "[") {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			writeEthJSON(w, ethErrorResponse(nil, &ethError{Code: ethErrParse, Message: "parse error"}))
+			return
+		}
+		if len(batch) == 0 {
+			writeEthJSON(w, ethErrorResponse(nil, &ethError{Code: ethErrInvalidRequest, Message: "empty batch"}))
+			return
+		}
+
+		responses := []map[string]interface{}{}
+		for _, raw := range batch {
+			if response := h.handleMessage(raw); response != nil {
+				responses = append(responses, response)
+			}
+		}
+		if len(responses) == 0 { // 모두 notification
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeEthJSON(w, responses)
+		return
+	}
+
+	response := h.handleMessage(body)
+	if response == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeEthJSON(w, response)
+}
+
+// 요청 하나를 처리해 응답 반환, notification(id 없음)이면 nil
+func (h *EthHandler) handleMessage(raw json.RawMessage) map[string]interface{} {
+	var req ethRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return ethErrorResponse(nil, &ethError{Code: ethErrParse, Message: "parse error"})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return ethErrorResponse(req.ID, &ethError{Code: ethErrInvalidRequest, Message: "invalid request"})
+	}
+
+	result, err := h.call(req.Method, req.Params)
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		var rpcErr *ethError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &ethError{Code: ethErrInternal, Message: err.Error()}
+		}
+		return ethErrorResponse(req.ID, rpcErr)
+	}
+
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      req.ID,
+		"result":  result,
+	}
+}
+
+// 메서드 이름으로 핸들러를 찾아 실행
+func (h *EthHandler) call(method string, rawParams json.RawMessage) (interface{}, error) {
+	handler, exists := h.methods[method]
+	if !exists {
+		return nil, &ethError{Code: ethErrMethodNotFound, Message: fmt.Sprintf("the method %s does not exist/is not available", method)}
+	}
+
+	var params []json.RawMessage
+	if len(rawParams) > 0 && string(rawParams) != "null" {
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, invalidParams("params must be an array")
+		}
+	}
+	return handler(params)
+}
+
+func ethErrorResponse(id json.RawMessage, err *ethError) map[string]interface{} {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   err,
+	}
+}
+
+func writeEthJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Printf("[RPC] Failed to write response : %v\n", err)
+	}
+}
+
+func invalidParams(message string) *ethError {
+	return &ethError{Code: ethErrInvalidParams, Message: message}
+}
+
+// i번째 파라미터를 v로 파싱, required가 아니면 없을 때 그대로 둠
+func parseParam(params []json.RawMessage, i int, required bool, v interface{}) error {
+	if i >= len(params) {
+		if required {
+			return invalidParams(fmt.Sprintf("missing value for required argument %d", i))
+		}
+		return nil
+	}
+	if err := json.Unmarshal(params[i], v); err != nil {
+		return invalidParams(fmt.Sprintf("invalid argument %d: %v", i, err))
+	}
+	return nil
+}
+
+// 블록 태그("latest", "earliest", "pending", 16진수 번호)를 블록 번호로 변환
+func resolveBlockTag(tag string) (uint64, error) {
+	switch tag {
+	case "", "latest", "pending", "safe", "finalized":
+		latest, err := blockchain.GetLatestBlock()
+		if err != nil {
+			return 0, err
+		}
+		return latest.Number, nil
+	case "earliest":
+		return 1, nil // 제네시스 블록 번호는 1
+	default:
+		number, err := hexutil.DecodeUint64(tag)
+		if err != nil {
+			return 0, invalidParams(fmt.Sprintf("invalid block tag: %s", tag))
+		}
+		return number, nil
+	}
+}
+
+// 주소 파라미터와 블록 태그를 파싱해 계정 조회 (없는 계정은 잔액, 논스 0)
+func (h *EthHandler) accountAt(params []json.RawMessage) (*account.Account, error) {
+	var address, tag string
+	if err := parseParam(params, 0, true, &address); err != nil {
+		return nil, err
+	}
+	if err := parseParam(params, 1, false, &tag); err != nil {
+		return nil, err
+	}
+	if !account.IsValidAddress(address) {
+		return nil, invalidParams(fmt.Sprintf("invalid address: %s", address))
+	}
+	number, err := resolveBlockTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	// 과거 블록은 상태 이력에서 조회 (보관 범위를 벗어나면 에러)
+	accountData, err := blockchain.GetAccountAt(address, number)
+	if errors.Is(err, db.ErrNotFound) {
+		return &account.Account{Balance: big.NewInt(0), Nonce: 0}, nil
+	}
+	if err != nil {
+		return nil, &ethError{Code: ethErrServer, Message: err.Error()}
+	}
+	return accountData, nil
+}
+
+func (h *EthHandler) blockNumber(params []json.RawMessage) (interface{}, error) {
+	latest, err := blockchain.GetLatestBlock()
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.EncodeUint64(latest.Number), nil
+}
+
+func (h *EthHandler) getBalance(params []json.RawMessage) (interface{}, error) {
+	accountData, err := h.accountAt(params)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.EncodeBig(accountData.Balance), nil
+}
+
+// 이더리움 도구는 이 값을 다음 트랜잭션의 nonce로 사용하므로, 마지막으로 사용된 계정 nonce + 1 반환
+// (이 체인의 첫 트랜잭션 nonce는 1, account.GetPendingNonce의 nextNonce와 같은 값)
+func (h *EthHandler) getTransactionCount(params []json.RawMessage) (interface{}, error) {
+	// "pending"이면 멤풀 pending 트랜잭션까지 반영
+	var address, tag string
+	if err := parseParam(params, 0, true, &address); err != nil {
+		return nil, err
+	}
+	if err := parseParam(params, 1, false, &tag); err != nil {
+		return nil, err
+	}
+	if tag == "pending" && account.IsValidAddress(address) {
+		state, err := blockchain.GetPendingAccountState(address)
+		if err != nil {
+			return nil, err
+		}
+		return hexutil.EncodeUint64(state.Nonce + 1), nil
+	}
+
+	accountData, err := h.accountAt(params)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.EncodeUint64(accountData.Nonce + 1), nil
+}
+
+func (h *EthHandler) getBlockByNumber(params []json.RawMessage) (interface{}, error) {
+	var tag string
+	var fullTx bool
+	if err := parseParam(params, 0, true, &tag); err != nil {
+		return nil, err
+	}
+	if err := parseParam(params, 1, false, &fullTx); err != nil {
+		return nil, err
+	}
+
+	number, err := resolveBlockTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	block, err := blockchain.GetBlockByNumber(number)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBlockBody(block); err != nil {
+		return nil, err
+	}
+	return toEthBlock(block, fullTx), nil
+}
+
+func (h *EthHandler) getBlockByHash(params []json.RawMessage) (interface{}, error) {
+	var hash string
+	var fullTx bool
+	if err := parseParam(params, 0, true, &hash); err != nil {
+		return nil, err
+	}
+	if err := parseParam(params, 1, false, &fullTx); err != nil {
+		return nil, err
+	}
+
+	block, err := blockchain.GetBlockByHash(hash)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBlockBody(block); err != nil {
+		return nil, err
+	}
+	return toEthBlock(block, fullTx), nil
+}
+
+// 본문을 정리한 블록은 트랜잭션이 없는 블록으로 보이지 않도록 에러 반환
+func checkBlockBody(block *blockchain.Block) error {
+	if blockchain.IsBodyPruned(block) {
+		return &ethError{Code: ethErrServer, Message: fmt.Sprintf("body of block %d has been pruned", block.Number)}
+	}
+	return nil
+}
+
+func (h *EthHandler) getTransactionByHash(params []json.RawMessage) (interface{}, error) {
+	var hash string
+	if err := parseParam(params, 0, true, &hash); err != nil {
+		return nil, err
+	}
+
+	// 1. 블록에 포함된 트랜잭션
+	tx, lookup, err := blockchain.GetTransactionByHash(hash)
+	if err == nil {
+		return toEthTransaction(*tx, lookup), nil
+	}
+	if !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+
+	// 2. 멤풀에 있는 트랜잭션
+	if poolTx, exists := blockchain.GetPoolTransaction(hash); exists {
+		return toEthTransaction(poolTx, nil), nil
+	}
+	return nil, nil
+}
+
+// data : 서명한 RawTransaction JSON을 0x 16진수로 인코딩한 값
+// 이 체인의 서명 메시지는 이더리움 트랜잭션과 다르므로 RLP로 인코딩한 이더리움 트랜잭션은 받지 않음
+func (h *EthHandler) sendRawTransaction(params []json.RawMessage) (interface{}, error) {
+	var data string
+	if err := parseParam(params, 0, true, &data); err != nil {
+		return nil, err
+	}
+	rawTransactionBytes, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, invalidParams(fmt.Sprintf("invalid raw transaction: %v", err))
+	}
+	if !json.Valid(rawTransactionBytes) {
+		return nil, invalidParams("raw transaction must be hex-encoded RawTransaction JSON, RLP-encoded transactions are not supported")
+	}
+
+	txHash, err := blockchain.SubmitTransaction(string(rawTransactionBytes))
+	if err != nil {
+		var txErr *blockchain.TxError
+		if errors.As(err, &txErr) {
+			return nil, &ethError{Code: ethErrServer, Message: txErr.Message, Data: TxErrorReply{Code: txErr.Code, Message: txErr.Message}}
+		}
+		return nil, err
+	}
+	return txHash, nil
+}
+
+func (h *EthHandler) chainID(params []json.RawMessage) (interface{}, error) {
+	return hexutil.EncodeUint64(constants.ChainID), nil
+}
+
+func (h *EthHandler) netVersion(params []json.RawMessage) (interface{}, error) {
+	return strconv.Itoa(constants.ChainID), nil
+}
+
+func (h *EthHandler) peerCount(params []json.RawMessage) (interface{}, error) {
+	return hexutil.EncodeUint64(uint64(p2p.PeerCount())), nil
+}
+
+func (h *EthHandler) clientVersion(params []json.RawMessage) (interface{}, error) {
+	return fmt.Sprintf("%s/%s/%s-%s/%s", constants.ClientName, constants.ClientVersion, runtime.GOOS, runtime.GOARCH, runtime.Version()), nil
+}
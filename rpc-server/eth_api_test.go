@@ -0,0 +1,95 @@
+package rpcserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"simple_p2p_client/blockchain"
+	"strings"
+	"testing"
+)
+
+func postEth(t *testing.T, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	NewEthHandler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestEthChainID(t *testing.T) {
+	rec := postEth(t, `{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`)
+
+	var res struct {
+		Result string `json:"result"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("invalid response %s: %v", rec.Body.String(), err)
+	}
+	if res.Result != "0x539" {
+		t.Errorf("expected chain id 0x539, got %s", res.Result)
+	}
+}
+
+func TestEthErrorsAndBatch(t *testing.T) {
+	rec := postEth(t, `[
+		{"jsonrpc":"2.0","id":1,"method":"web3_clientVersion"},
+		{"jsonrpc":"2.0","id":2,"method":"eth_unknown"},
+		{"jsonrpc":"2.0","method":"eth_chainId"},
+		{"jsonrpc":"2.0","id":3,"method":"eth_getBalance","params":["not-an-address","latest"]}
+	]`)
+
+	var responses []struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *ethError       `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &responses); err != nil {
+		t.Fatalf("invalid response %s: %v", rec.Body.String(), err)
+	}
+
+	// notification(id 없음)은 응답하지 않음
+	if len(responses) != 3 {
+		t.Fatalf("expected 3 responses, got %d", len(responses))
+	}
+	if responses[0].Error != nil || !strings.Contains(string(responses[0].Result), "simple-blockchain-client") {
+		t.Errorf("unexpected clientVersion response: %+v", responses[0])
+	}
+	if responses[1].Error == nil || responses[1].Error.Code != ethErrMethodNotFound {
+		t.Errorf("expected method not found, got %+v", responses[1])
+	}
+	if responses[2].Error == nil || responses[2].Error.Code != ethErrInvalidParams {
+		t.Errorf("expected invalid params, got %+v", responses[2])
+	}
+}
+
+func TestEthParseError(t *testing.T) {
+	rec := postEth(t, `{"jsonrpc":"2.0",`)
+
+	var res struct {
+		Error *ethError `json:"error"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &res)
+	if res.Error == nil || res.Error.Code != ethErrParse {
+		t.Errorf("expected parse error, got %s", rec.Body.String())
+	}
+}
+
+func TestDBCompactRequiresLocalhost(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/rpc", nil)
+	req.RemoteAddr = "192.0.2.10:40000"
+	err := (&DBAPI{}).Compact(req, &DBCompactArgs{}, &DBCompactReply{})
+	if err == nil || !strings.Contains(err.Error(), "localhost") {
+		t.Errorf("expected remote compaction to be rejected, got %v", err)
+	}
+}
+
+func TestCheckBlockBody(t *testing.T) {
+	empty := &blockchain.Block{Number: 3, MerkleRoot: "0x0", Transaction: []blockchain.Transaction{}}
+	if err := checkBlockBody(empty); err != nil {
+		t.Errorf("expected block without transactions to be served, got %v", err)
+	}
+	pruned := &blockchain.Block{Number: 4, MerkleRoot: "0xabc", Transaction: []blockchain.Transaction{}}
+	if err := checkBlockBody(pruned); err == nil || !strings.Contains(err.Error(), "pruned") {
+		t.Errorf("expected pruned block error, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package rpcserver
+
+import (
+	"math/big"
+	"simple_p2p_client/blockchain"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// 블록 헤더 (newHeads 구독 알림에도 사용)
+type EthHeader struct {
+	Number           string `json:"number"`
+	Hash             string `json:"hash"`
+	ParentHash       string `json:"parentHash"`
+	Timestamp        string `json:"timestamp"`
+	TransactionsRoot string `json:"transactionsRoot"`
+	Miner            string `json:"miner"`
+}
+
+// eth_getBlockBy* 응답 블록
+type EthBlock struct {
+	EthHeader
+	Transactions []interface{} `json:"transactions"` // 해시 목록 또는 EthTransaction 목록
+}
+
+// eth_getTransactionByHash 응답 트랜잭션, 멤풀에 있으면 블록 정보는 null
+type EthTransaction struct {
+	Hash             string  `json:"hash"`
+	From             string  `json:"from"`
+	To               string  `json:"to"`
+	Value            string  `json:"value"`
+	Nonce            string  `json:"nonce"`
+	Fee              string  `json:"fee"`
+	Input            string  `json:"input"`
+	BlockHash        *string `json:"blockHash"`
+	BlockNumber      *string `json:"blockNumber"`
+	TransactionIndex *string `json:"transactionIndex"`
+}
+
+// DB에는 0x 없이 저장된 해시에 0x 접두사 부착
+func with0x(hash string) string {
+	if strings.HasPrefix(hash, "0x") {
+		return hash
+	}
+	return "0x" + hash
+}
+
+func toEthHeader(block *blockchain.Block) EthHeader {
+	return EthHeader{
+		Number:           hexutil.EncodeUint64(block.Number),
+		Hash:             with0x(block.Hash),
+		ParentHash:       with0x(block.ParentHash),
+		Timestamp:        hexutil.EncodeUint64(block.Timestamp),
+		TransactionsRoot: with0x(block.MerkleRoot),
+		Miner:            block.Miner,
+	}
+}
+
+func toEthBlock(block *blockchain.Block, fullTx bool) EthBlock {
+	ethBlock := EthBlock{
+		EthHeader:    toEthHeader(block),
+		Transactions: []interface{}{},
+	}
+
+	for i, tx := range block.Transaction {
+		if fullTx {
+			ethBlock.Transactions = append(ethBlock.Transactions, toEthTransaction(tx, &blockchain.TxLookup{
+				BlockHash:   block.Hash,
+				BlockNumber: block.Number,
+				Index:       i,
+			}))
+		} else {
+			ethBlock.Transactions = append(ethBlock.Transactions, tx.Hash)
+		}
+	}
+	return ethBlock
+}
+
+func toEthTransaction(tx blockchain.Transaction, lookup *blockchain.TxLookup) EthTransaction {
+	value := tx.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+
+	ethTx := EthTransaction{
+		Hash:  tx.Hash,
+		From:  tx.From,
+		To:    tx.To,
+		Value: hexutil.EncodeBig(value),
+		Nonce: hexutil.EncodeUint64(tx.Nonce),
+		Fee:   hexutil.EncodeBig(tx.FeeOrZero()),
+		Input: "0x",
+	}
+	if lookup != nil {
+		blockHash := with0x(lookup.BlockHash)
+		blockNumber := hexutil.EncodeUint64(lookup.BlockNumber)
+		index := hexutil.EncodeUint64(uint64(lookup.Index))
+		ethTx.BlockHash = &blockHash
+		ethTx.BlockNumber = &blockNumber
+		ethTx.TransactionIndex = &index
+	}
+	return ethTx
+}
@@ -0,0 +1,286 @@
+package rpcserver
+
+import (
+	encodingJson "encoding/json"
+	"fmt"
+	"net/http"
+	"simple_p2p_client/account"
+	"simple_p2p_client/blockchain"
+	"simple_p2p_client/keystore"
+	"simple_p2p_client/utils"
+	"simple_p2p_client/wallet"
+	"strings"
+	"time"
+)
+
+const (
+	defaultUnlockDuration = 300 // 기본 잠금 해제 시간 (초)
+	maxMnemonicAccounts   = 100 // ImportMnemonic 한 번에 저장할 수 있는 최대 계정 수
+)
+
+// 노드 키 저장소의 계정 관리 (노드와 같은 머신에서만 호출 가능)
+type PersonalAPI struct{}
+
+type PersonalNewAccountArgs struct {
+	Password string `json:"password"`
+}
+type PersonalNewAccountReply struct {
+	Address string `json:"address"`
+}
+
+// 새 키를 만들어 비밀번호로 암호화해 키 저장소에 저장 (개인키는 반환하지 않음)
+func (p *PersonalAPI) NewAccount(r *http.Request, args *PersonalNewAccountArgs, reply *PersonalNewAccountReply) error {
+	ks, err := getLocalKeyStore(r)
+	if err != nil {
+		return err
+	}
+
+	address, err := ks.NewAccount(args.Password)
+	if err != nil {
+		return err
+	}
+	reply.Address = address
+	return nil
+}
+
+type PersonalImportRawKeyArgs struct {
+	PrivateKey string `json:"privateKey"` // 16진수 개인키
+	Password   string `json:"password"`
+}
+
+// 기존 개인키를 비밀번호로 암호화해 키 저장소에 저장 (제네시스 miner 키 등록 등)
+func (p *PersonalAPI) ImportRawKey(r *http.Request, args *PersonalImportRawKeyArgs, reply *PersonalNewAccountReply) error {
+	ks, err := getLocalKeyStore(r)
+	if err != nil {
+		return err
+	}
+
+	privateKey, err := account.LoadPrivateKey(strings.TrimPrefix(args.PrivateKey, "0x"))
+	if err != nil {
+		return err
+	}
+	address, err := ks.ImportECDSA(privateKey, args.Password)
+	if err != nil {
+		return err
+	}
+	reply.Address = address
+	return nil
+}
+
+type PersonalImportMnemonicArgs struct {
+	Mnemonic   string `json:"mnemonic"`
+	Passphrase string `json:"passphrase"` // BIP-39 추가 비밀번호, 생략 가능
+	Start      uint32 `json:"start"`      // 첫 계정 번호 (m/44'/60'/0'/0/start)
+	Count      uint32 `json:"count"`      // 생략하면 1
+	Password   string `json:"password"`   // 키 파일 암호화 비밀번호
+}
+type PersonalImportMnemonicReply struct {
+	Addresses []string `json:"addresses"` // 새로 저장된 주소 (이미 있던 계정 제외)
+}
+
+// 니모닉에서 MetaMask와 같은 경로로 계정을 유도해 키 저장소에 저장
+func (p *PersonalAPI) ImportMnemonic(r *http.Request, args *PersonalImportMnemonicArgs, reply *PersonalImportMnemonicReply) error {
+	ks, err := getLocalKeyStore(r)
+	if err != nil {
+		return err
+	}
+
+	count := args.Count
+	if count == 0 {
+		count = 1
+	}
+	if count > maxMnemonicAccounts {
+		return fmt.Errorf("count must be at most %d", maxMnemonicAccounts)
+	}
+
+	w, err := wallet.NewFromMnemonic(args.Mnemonic, args.Passphrase)
+	if err != nil {
+		return err
+	}
+	reply.Addresses, err = w.ImportToKeyStore(ks, args.Start, count, args.Password)
+	return err
+}
+
+type PersonalListAccountsArgs struct{}
+type PersonalAccount struct {
+	Address  string `json:"address"`
+	Unlocked bool   `json:"unlocked"`
+}
+type PersonalListAccountsReply struct {
+	Accounts []PersonalAccount `json:"accounts"`
+}
+
+// 키 저장소의 계정과 잠금 해제 여부 조회
+func (p *PersonalAPI) ListAccounts(r *http.Request, args *PersonalListAccountsArgs, reply *PersonalListAccountsReply) error {
+	ks, err := getLocalKeyStore(r)
+	if err != nil {
+		return err
+	}
+
+	addresses, err := ks.Accounts()
+	if err != nil {
+		return err
+	}
+	reply.Accounts = make([]PersonalAccount, 0, len(addresses))
+	for _, address := range addresses {
+		reply.Accounts = append(reply.Accounts, PersonalAccount{Address: address, Unlocked: ks.IsUnlocked(address)})
+	}
+	return nil
+}
+
+type PersonalUnlockArgs struct {
+	Address  string  `json:"address"`
+	Password string  `json:"password"`
+	Duration *uint64 `json:"duration"` // 초, 생략하면 300, 0이면 Lock 호출 전까지
+}
+type PersonalUnlockReply struct {
+	Unlocked bool `json:"unlocked"`
+}
+
+// 비밀번호로 계정 잠금 해제
+func (p *PersonalAPI) Unlock(r *http.Request, args *PersonalUnlockArgs, reply *PersonalUnlockReply) error {
+	ks, err := getLocalKeyStore(r)
+	if err != nil {
+		return err
+	}
+
+	duration := uint64(defaultUnlockDuration)
+	if args.Duration != nil {
+		duration = *args.Duration
+	}
+	if err := ks.Unlock(args.Address, args.Password, time.Duration(duration)*time.Second); err != nil {
+		return err
+	}
+	reply.Unlocked = true
+	return nil
+}
+
+type PersonalLockArgs struct {
+	Address string `json:"address"`
+}
+type PersonalLockReply struct {
+	Locked bool `json:"locked"`
+}
+
+// 잠금 해제된 키를 메모리에서 제거
+func (p *PersonalAPI) Lock(r *http.Request, args *PersonalLockArgs, reply *PersonalLockReply) error {
+	ks, err := getLocalKeyStore(r)
+	if err != nil {
+		return err
+	}
+
+	ks.Lock(args.Address)
+	reply.Locked = true
+	return nil
+}
+
+type PersonalTransactionArgs struct {
+	From  string  `json:"from"`
+	To    string  `json:"to"`
+	Value string  `json:"value"`
+	Nonce *uint64 `json:"nonce"` // 생략하면 pending 상태의 다음 nonce
+	Fee   string  `json:"fee"`   // 생략 가능, 10진수 문자열
+}
+
+type PersonalSignTransactionReply struct {
+	Nonce     uint64 `json:"nonce"`
+	Signature string `json:"signature"`
+}
+
+// 잠금 해제된 키로 트랜잭션 서명 (제출하지 않음, transaction.SendTransaction에 그대로 사용 가능)
+func (p *PersonalAPI) SignTransaction(r *http.Request, args *PersonalTransactionArgs, reply *PersonalSignTransactionReply) error {
+	ks, err := getLocalKeyStore(r)
+	if err != nil {
+		return err
+	}
+
+	rawTransaction, err := signWithKeyStore(ks, args)
+	if err != nil {
+		return err
+	}
+	reply.Nonce = rawTransaction.Nonce
+	reply.Signature = rawTransaction.Signature
+	return nil
+}
+
+// 잠금 해제된 키로 서명해 멤풀에 제출
+func (p *PersonalAPI) SendTransaction(r *http.Request, args *PersonalTransactionArgs, reply *SendTransactionReply) error {
+	ks, err := getLocalKeyStore(r)
+	if err != nil {
+		return err
+	}
+
+	rawTransaction, err := signWithKeyStore(ks, args)
+	if err != nil {
+		return err
+	}
+	rawTransactionBytes, err := encodingJson.Marshal(rawTransaction)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction: %v", err)
+	}
+
+	txHash, err := blockchain.SubmitTransaction(string(rawTransactionBytes))
+	if err != nil {
+		return toRPCError(err)
+	}
+	reply.TxHash = txHash
+	return nil
+}
+
+// 인자를 검증하고 nonce를 채워 서명한 트랜잭션
+func signWithKeyStore(ks *keystore.KeyStore, args *PersonalTransactionArgs) (*blockchain.RawTransaction, error) {
+	if !account.IsValidAddress(args.From) || !account.IsValidAddress(args.To) {
+		return nil, toRPCError(&blockchain.TxError{Code: blockchain.TxErrInvalid, Message: "invalid from or to address"})
+	}
+	value, err := utils.ConvertStringToBigInt(args.Value)
+	if err != nil {
+		return nil, toRPCError(&blockchain.TxError{Code: blockchain.TxErrInvalid, Message: fmt.Sprintf("failed to convert value to big.int: %v", err)})
+	}
+	fee, err := parseOptionalFee(args.Fee)
+	if err != nil {
+		return nil, toRPCError(&blockchain.TxError{Code: blockchain.TxErrInvalid, Message: err.Error()})
+	}
+
+	nonce, err := resolveNonce(args.From, args.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := ks.SignHash(args.From, blockchain.TransactionSigningHash(args.From, args.To, value, nonce, fee))
+	if err != nil {
+		return nil, err
+	}
+
+	return &blockchain.RawTransaction{
+		From:      args.From,
+		To:        args.To,
+		Value:     value,
+		Nonce:     nonce,
+		Fee:       fee,
+		Signature: signature,
+	}, nil
+}
+
+// nonce가 생략되면 멤풀 pending까지 반영한 다음 nonce
+func resolveNonce(address string, nonce *uint64) (uint64, error) {
+	if nonce != nil {
+		return *nonce, nil
+	}
+	state, err := blockchain.GetPendingAccountState(address)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve pending state: %v", err)
+	}
+	return state.Nonce + 1, nil
+}
+
+// localhost 요청이면 노드 키 저장소 반환
+func getLocalKeyStore(r *http.Request) (*keystore.KeyStore, error) {
+	if !isLocalRequest(r) {
+		return nil, fmt.Errorf("personal namespace is only available from localhost")
+	}
+	ks := keystore.GetKeyStore()
+	if ks == nil {
+		return nil, fmt.Errorf("keystore is not initialized")
+	}
+	return ks, nil
+}
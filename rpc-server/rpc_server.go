@@ -2,8 +2,10 @@ package rpcserver
 
 import (
 	"fmt"
-
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/rpc/v2"
 	"github.com/gorilla/rpc/v2/json"
@@ -12,8 +14,22 @@ import (
 type RpcService struct {
 }
 
-// 서버 초기화 및 공통 설정
-func StartRpcServer(port int) {
+// 네임스페이스별 RPC 서비스 (eth는 아래에서 / 와 /ws로 따로 등록)
+func rpcServices() map[string]interface{} {
+	return map[string]interface{}{
+		"rpc":         new(RpcService),
+		"block":       new(BlockAPI),
+		"transaction": new(TransactionAPI),
+		"account":     new(AccountAPI),
+		"txpool":      new(TxPoolAPI),
+		"chain":       new(ChainAPI),
+		"personal":    new(PersonalAPI),
+		"db":          new(DBAPI),
+	}
+}
+
+// 서버 초기화 및 공통 설정 (host가 비어 있으면 모든 인터페이스, namespaces에 있는 서비스만 등록)
+func StartRpcServer(host string, port int, namespaces []string) {
 
 	// Create Gorilla RPC server
 	server := rpc.NewServer()
@@ -22,23 +38,38 @@ func StartRpcServer(port int) {
 	server.RegisterCodec(json.NewCodec(), "application/json")
 
 	// Register RPC Service
-	server.RegisterService(new(RpcService), "rpc")
-	server.RegisterService(new(BlockAPI), "block")
-
-	server.RegisterService(new(TransactionAPI), "transaction")
-
-	server.RegisterService(new(AccountAPI), "account")
+	services := rpcServices()
+	enableEth := false
+	for _, namespace := range namespaces {
+		if namespace == "eth" {
+			enableEth = true
+			continue
+		}
+		service, exists := services[namespace]
+		if !exists {
+			fmt.Printf("[RPC] Unknown namespace %q, skipping\n", namespace)
+			continue
+		}
+		server.RegisterService(service, namespace)
+	}
 
 	// Set HTTP Handler
 	http.Handle("/rpc", server)
 
+	if enableEth {
+		// 이더리움 JSON-RPC 2.0 호환 엔드포인트 (eth_, net_, web3_)
+		http.Handle("/", NewEthHandler())
+
+		// 웹소켓 구독 (newHeads, newPendingTransactions, accountChanges)
+		http.HandleFunc("/ws", ServeWebSocket)
+	}
+
 	// Start Server
-	fmt.Printf("[RPC] Server is listening on : %d\n", port)
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	fmt.Printf("[RPC] Server is listening on : %s (namespaces : %s)\n", address, strings.Join(namespaces, ", "))
 
-	err := http.ListenAndServe(fmt.Sprintf(":%d", port), nil)
+	err := http.ListenAndServe(address, nil)
 	if err != nil {
 		fmt.Printf("Error starting RPC Server: %v\n", err)
 	}
-	fmt.Printf("[RPC] Server is listening on : %d\n", port)
-
 }
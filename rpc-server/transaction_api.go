@@ -2,13 +2,14 @@ package rpcserver
 
 import (
 	encodingJson "encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
-	"simple_p2p_client/account"
 	"simple_p2p_client/blockchain"
-	"simple_p2p_client/mediator"
-	"simple_p2p_client/protocol_constants"
 	"simple_p2p_client/utils"
+
+	"github.com/gorilla/rpc/v2/json"
 )
 
 type TransactionAPI struct{}
@@ -18,6 +19,7 @@ type SendTransactionArgs struct {
 	To        string `json:"to"`
 	Value     string `json:"value"`
 	Nonce     uint64 `json:"nonce"`
+	Fee       string `json:"fee"` // 생략 가능, 10진수 문자열
 	Signature string `json:"signature"`
 }
 
@@ -32,7 +34,12 @@ func (s *TransactionAPI) SendTransaction(r *http.Request, args *SendTransactionA
 
 	valueBigInt, err := utils.ConvertStringToBigInt(args.Value)
 	if err != nil {
-		return fmt.Errorf("failed to convert value to big.int: %v", err)
+		return toRPCError(&blockchain.TxError{Code: blockchain.TxErrInvalid, Message: fmt.Sprintf("failed to convert value to big.int: %v", err)})
+	}
+
+	feeBigInt, err := parseOptionalFee(args.Fee)
+	if err != nil {
+		return toRPCError(&blockchain.TxError{Code: blockchain.TxErrInvalid, Message: err.Error()})
 	}
 
 	rawTransaction := blockchain.RawTransaction{
@@ -40,6 +47,7 @@ func (s *TransactionAPI) SendTransaction(r *http.Request, args *SendTransactionA
 		To:        args.To,
 		Value:     valueBigInt,
 		Nonce:     args.Nonce,
+		Fee:       feeBigInt,
 		Signature: args.Signature,
 	}
 
@@ -48,55 +56,41 @@ func (s *TransactionAPI) SendTransaction(r *http.Request, args *SendTransactionA
 		return fmt.Errorf("failed to marshal transaction: %v", err)
 	}
 
-	// 2. 트랜잭션 메시지를 채널로 전달
-
-	mediatorInstance := mediator.GetMediatorInstance()
-	mediatorInstance.P2PToBlockchain <- fmt.Sprintf("%c%s", protocol_constants.P2PTransactionMessage, string(rawTransactionBytes))
-
-	// 3. 트랜잭션 해시값 생성 및 반환
-	transactionHash := utils.Keccak256Hex(rawTransactionBytes)
-	reply.TxHash = transactionHash
+	// 2. 멤풀에 동기적으로 제출 (검증 실패 시 코드가 붙은 에러 반환)
+	txHash, err := blockchain.SubmitTransaction(string(rawTransactionBytes))
+	if err != nil {
+		return toRPCError(err)
+	}
 
-	// fmt.Println("Transaction sent to mediator. Hash:", transactionHash)
+	// 3. 트랜잭션 해시값 반환
+	reply.TxHash = txHash
 	return nil
 
 }
 
-type SendRawTransactionArgs struct {
-	From  string `json:"from"`
-	To    string `json:"to"`
-	Value string `json:"value"`
-	Nonce uint64 `json:"nonce"`
+// 트랜잭션 제출 실패 시 error 필드에 담기는 내용
+type TxErrorReply struct {
+	Code    blockchain.TxErrorCode `json:"code"`
+	Message string                 `json:"message"`
 }
 
-type SendRawTransactionReply struct {
-	Signature string `json:"signature"`
-}
-
-// TO BE DEPRECATED : 특정 주소의 서명값만 반환 가능. 테스트 용도, 이후 삭제
-// 서명되지 않은 트랜잭션 전달(서명 반환)
-func (s *TransactionAPI) SendRawTransaction(r *http.Request, args *SendRawTransactionArgs, reply *SendRawTransactionReply) error {
-
-	// 1. 메시지 생성 (from, to, value, nonce를 연결)
-	message := fmt.Sprintf("%s%s%s%d", args.From, args.To, args.Value, args.Nonce)
-
-	// 2. 메시지 해싱 (Keccak256)
-	messageHash := utils.Keccak256([]byte(message))
-
-	// 3. 개인 키 로드 (개인 키를 안전하게 관리해야 함)
-	privateKeyHex := "7ac125dda168b44ee9fc0d8db3a804ef86b3cc50206a0112b25373d622cf78f7" // 실제 서비스에서는 안전한 방식으로 관리 필요
-	privateKey, err := account.LoadPrivateKey(privateKeyHex)                            // account 패키지에서 개인 키 로드 함수 구현 필요
-	if err != nil {
-		return fmt.Errorf("failed to load private key: %v", err)
+// 수수료 문자열 변환, 비어 있으면 nil (수수료 없음)
+func parseOptionalFee(fee string) (*big.Int, error) {
+	if fee == "" {
+		return nil, nil
 	}
-
-	// 4. 메시지 서명
-	signature, err := account.SignMessage(messageHash, privateKey)
+	feeBigInt, err := utils.ConvertStringToBigInt(fee)
 	if err != nil {
-		return fmt.Errorf("failed to sign transaction: %v", err)
+		return nil, fmt.Errorf("failed to convert fee to big.int: %v", err)
 	}
+	return feeBigInt, nil
+}
 
-	// 5. 서명 반환
-	reply.Signature = signature
-	return nil
+// blockchain.TxError를 {code, message} 형태의 RPC 에러로 변환
+func toRPCError(err error) error {
+	var txErr *blockchain.TxError
+	if errors.As(err, &txErr) {
+		return &json.Error{Data: TxErrorReply{Code: txErr.Code, Message: txErr.Message}}
+	}
+	return err
 }
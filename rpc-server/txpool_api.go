@@ -0,0 +1,137 @@
+package rpcserver
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"simple_p2p_client/account"
+	"simple_p2p_client/blockchain"
+)
+
+type TxPoolAPI struct{}
+
+type TxPoolStatusArgs struct{}
+type TxPoolStatusReply struct {
+	Pending int `json:"pending"`
+	Future  int `json:"future"`
+}
+
+// pending, future 트랜잭션 수 조회
+func (t *TxPoolAPI) Status(r *http.Request, args *TxPoolStatusArgs, reply *TxPoolStatusReply) error {
+	mempool, err := getMempool()
+	if err != nil {
+		return err
+	}
+
+	reply.Pending, reply.Future = mempool.Status()
+	return nil
+}
+
+type TxPoolContentArgs struct{}
+type TxPoolContentReply struct {
+	Pending map[string]map[uint64]blockchain.Transaction `json:"pending"` // 주소 => nonce => 트랜잭션
+	Future  map[string]map[uint64]blockchain.Transaction `json:"future"`
+}
+
+// 멤풀의 모든 트랜잭션을 주소, 큐별로 조회
+func (t *TxPoolAPI) Content(r *http.Request, args *TxPoolContentArgs, reply *TxPoolContentReply) error {
+	mempool, err := getMempool()
+	if err != nil {
+		return err
+	}
+
+	reply.Pending, reply.Future = mempool.Content()
+	return nil
+}
+
+type TxPoolContentFromArgs struct {
+	Address string `json:"address"`
+}
+type TxPoolContentFromReply struct {
+	Pending map[uint64]blockchain.Transaction `json:"pending"` // nonce => 트랜잭션
+	Future  map[uint64]blockchain.Transaction `json:"future"`
+}
+
+// 특정 주소의 트랜잭션 조회
+func (t *TxPoolAPI) ContentFrom(r *http.Request, args *TxPoolContentFromArgs, reply *TxPoolContentFromReply) error {
+	if !account.IsValidAddress(args.Address) {
+		return fmt.Errorf("invalid address format: %s", args.Address)
+	}
+	mempool, err := getMempool()
+	if err != nil {
+		return err
+	}
+
+	reply.Pending, reply.Future = mempool.ContentFrom(args.Address)
+	return nil
+}
+
+type TxPoolInspectArgs struct{}
+type TxPoolInspectReply struct {
+	Pending map[string]map[uint64]string `json:"pending"` // 주소 => nonce => 요약
+	Future  map[string]map[uint64]string `json:"future"`
+}
+
+// 멤풀의 트랜잭션을 한 줄 요약으로 조회
+func (t *TxPoolAPI) Inspect(r *http.Request, args *TxPoolInspectArgs, reply *TxPoolInspectReply) error {
+	mempool, err := getMempool()
+	if err != nil {
+		return err
+	}
+
+	pending, future := mempool.Content()
+	reply.Pending = summarizeQueue(pending)
+	reply.Future = summarizeQueue(future)
+	return nil
+}
+
+type TxPoolRemoveArgs struct {
+	Hash string `json:"hash"`
+}
+type TxPoolRemoveReply struct {
+	Removed bool `json:"removed"`
+}
+
+// 관리자 전용 : 해시로 멤풀에서 트랜잭션 제거 (노드와 같은 머신에서만 호출 가능)
+func (t *TxPoolAPI) Remove(r *http.Request, args *TxPoolRemoveArgs, reply *TxPoolRemoveReply) error {
+	if !isLocalRequest(r) {
+		return fmt.Errorf("txpool.Remove is only available from localhost")
+	}
+	mempool, err := getMempool()
+	if err != nil {
+		return err
+	}
+
+	reply.Removed = mempool.RemoveTransaction(args.Hash)
+	return nil
+}
+
+func getMempool() (*blockchain.Mempool, error) {
+	mempool := blockchain.GetMempool()
+	if mempool == nil {
+		return nil, fmt.Errorf("mempool is not initialized")
+	}
+	return mempool, nil
+}
+
+// 요청이 루프백 주소에서 왔는지 확인
+func isLocalRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// 주소 => nonce => "to: value" 형태의 요약
+func summarizeQueue(queue map[string]map[uint64]blockchain.Transaction) map[string]map[uint64]string {
+	summary := make(map[string]map[uint64]string, len(queue))
+	for address, txs := range queue {
+		summary[address] = make(map[uint64]string, len(txs))
+		for nonce, tx := range txs {
+			summary[address][nonce] = fmt.Sprintf("%s: %s", tx.To, tx.Value.String())
+		}
+	}
+	return summary
+}
@@ -0,0 +1,272 @@
+package rpcserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"simple_p2p_client/account"
+	"simple_p2p_client/blockchain"
+	"simple_p2p_client/mediator"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gorilla/websocket"
+)
+
+// 구독 종류
+const (
+	wsNewHeads       = "newHeads"               // 새 블록 헤더
+	wsPendingTxs     = "newPendingTransactions" // 멤풀 pending에 들어온 트랜잭션 해시
+	wsAccountChanges = "accountChanges"         // 감시 주소의 잔액, 논스 변경
+)
+
+const (
+	wsSendBuffer   = 256              // 연결별 전송 대기 메시지 수
+	wsEventBuffer  = 256              // 연결별 이벤트 버스 구독 버퍼 (가득 차면 버림)
+	wsWriteTimeout = 10 * time.Second // 메시지 하나를 쓰는 최대 시간
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true }, // 대시보드 등 다른 origin 허용
+}
+
+// 연결 안의 구독 하나
+type wsSubscription struct {
+	kind         string
+	address      string // accountChanges일 때 감시할 주소
+	notifyMethod string // "subscription" 또는 "eth_subscription"
+}
+
+// accountChanges 알림 내용
+type AccountChangeNotification struct {
+	Address     string `json:"address"`
+	Balance     string `json:"balance"`
+	Nonce       uint64 `json:"nonce"`
+	BlockNumber uint64 `json:"blockNumber"`
+}
+
+// 웹소켓 연결 하나 : 쓰기는 writeLoop에서만 함
+type wsConn struct {
+	conn   *websocket.Conn
+	send   chan interface{}
+	done   chan struct{} // 연결 종료
+	failed chan struct{} // 쓰기 실패로 writeLoop 종료
+	mu     sync.Mutex
+	subs   map[string]wsSubscription
+	nextID uint64
+}
+
+// "/ws" : subscribe/unsubscribe를 처리하고 이벤트 버스의 이벤트를 알림으로 전송
+func ServeWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		fmt.Printf("[RPC] WebSocket upgrade failed : %v\n", err)
+		return
+	}
+	fmt.Printf("[RPC] WebSocket connected : %s\n", conn.RemoteAddr().String())
+
+	c := &wsConn{
+		conn:   conn,
+		send:   make(chan interface{}, wsSendBuffer),
+		done:   make(chan struct{}),
+		failed: make(chan struct{}),
+		subs:   make(map[string]wsSubscription),
+	}
+
+	// 느린 클라이언트가 블록 처리를 막지 않도록 PolicyDrop으로 구독
+	busSub := mediator.GetMediatorInstance().Subscribe(wsEventBuffer, mediator.PolicyDrop,
+		mediator.ChainHeadEventType, mediator.NewPendingTxsEventType, mediator.AccountChangedEventType)
+
+	go c.writeLoop()
+	go c.eventLoop(busSub)
+
+	c.readLoop()
+
+	busSub.Unsubscribe()
+	close(c.done)
+	conn.Close()
+	fmt.Printf("[RPC] WebSocket disconnected : %s\n", conn.RemoteAddr().String())
+}
+
+// 클라이언트 요청 처리, 연결이 끊기면 반환
+func (c *wsConn) readLoop() {
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req ethRequest
+		if err := json.Unmarshal(message, &req); err != nil {
+			c.enqueue(ethErrorResponse(nil, &ethError{Code: ethErrParse, Message: "parse error"}))
+			continue
+		}
+
+		result, err := c.handleRequest(req)
+		if req.ID == nil {
+			continue
+		}
+		if err != nil {
+			var rpcErr *ethError
+			if !errors.As(err, &rpcErr) {
+				rpcErr = &ethError{Code: ethErrInternal, Message: err.Error()}
+			}
+			c.enqueue(ethErrorResponse(req.ID, rpcErr))
+			continue
+		}
+		c.enqueue(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  result,
+		})
+	}
+}
+
+func (c *wsConn) handleRequest(req ethRequest) (interface{}, error) {
+	var params []json.RawMessage
+	if len(req.Params) > 0 && string(req.Params) != "null" {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams("params must be an array")
+		}
+	}
+
+	switch req.Method {
+	case "subscribe", "eth_subscribe":
+		notifyMethod := "subscription"
+		if req.Method == "eth_subscribe" {
+			notifyMethod = "eth_subscription"
+		}
+		return c.subscribe(params, notifyMethod)
+
+	case "unsubscribe", "eth_unsubscribe":
+		var id string
+		if err := parseParam(params, 0, true, &id); err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		_, exists := c.subs[id]
+		delete(c.subs, id)
+		c.mu.Unlock()
+		return exists, nil
+
+	default:
+		return nil, &ethError{Code: ethErrMethodNotFound, Message: fmt.Sprintf("the method %s does not exist/is not available", req.Method)}
+	}
+}
+
+// params : [종류] 또는 ["accountChanges", 주소]
+func (c *wsConn) subscribe(params []json.RawMessage, notifyMethod string) (interface{}, error) {
+	var kind string
+	if err := parseParam(params, 0, true, &kind); err != nil {
+		return nil, err
+	}
+
+	sub := wsSubscription{kind: kind, notifyMethod: notifyMethod}
+	switch kind {
+	case wsNewHeads, wsPendingTxs:
+	case wsAccountChanges:
+		if err := parseParam(params, 1, true, &sub.address); err != nil {
+			return nil, err
+		}
+		if !account.IsValidAddress(sub.address) {
+			return nil, invalidParams(fmt.Sprintf("invalid address: %s", sub.address))
+		}
+	default:
+		return nil, invalidParams(fmt.Sprintf("unknown subscription: %s", kind))
+	}
+
+	c.mu.Lock()
+	c.nextID++
+	id := hexutil.EncodeUint64(c.nextID)
+	c.subs[id] = sub
+	c.mu.Unlock()
+
+	return id, nil
+}
+
+// 이벤트 버스의 이벤트를 구독 종류에 맞게 알림으로 변환
+func (c *wsConn) eventLoop(busSub *mediator.Subscription) {
+	for event := range busSub.Events() {
+
+		switch ev := event.(type) {
+		case mediator.ChainHeadEvent:
+			var block blockchain.Block
+			if err := json.Unmarshal([]byte(ev.Payload), &block); err != nil {
+				fmt.Printf("[RPC] Failed to parse head block : %v\n", err)
+				continue
+			}
+			c.notify(func(sub wsSubscription) bool { return sub.kind == wsNewHeads }, toEthHeader(&block))
+
+		case mediator.NewPendingTxsEvent:
+			// pending에 들어간 트랜잭션만 알림 (future에 들어간 트랜잭션은 승격될 때 알림)
+			for _, hash := range ev.Hashes {
+				c.notify(func(sub wsSubscription) bool { return sub.kind == wsPendingTxs }, hash)
+			}
+
+		case mediator.AccountChangedEvent:
+			c.notify(func(sub wsSubscription) bool {
+				return sub.kind == wsAccountChanges && strings.EqualFold(sub.address, ev.Address)
+			}, AccountChangeNotification{
+				Address:     ev.Address,
+				Balance:     ev.Balance,
+				Nonce:       ev.Nonce,
+				BlockNumber: ev.BlockNumber,
+			})
+		}
+	}
+}
+
+// 조건에 맞는 구독마다 알림 전송
+func (c *wsConn) notify(match func(sub wsSubscription) bool, result interface{}) {
+	c.mu.Lock()
+	var notifications []interface{}
+	for id, sub := range c.subs {
+		if !match(sub) {
+			continue
+		}
+		notifications = append(notifications, map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  sub.notifyMethod,
+			"params": map[string]interface{}{
+				"subscription": id,
+				"result":       result,
+			},
+		})
+	}
+	c.mu.Unlock()
+
+	for _, notification := range notifications {
+		c.enqueue(notification)
+	}
+}
+
+// 전송 대기열에 추가, 연결이 닫혔으면 버림
+func (c *wsConn) enqueue(message interface{}) {
+	select {
+	case c.send <- message:
+	case <-c.done:
+	case <-c.failed:
+	}
+}
+
+func (c *wsConn) writeLoop() {
+	for {
+		select {
+		case message := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := c.conn.WriteJSON(message); err != nil {
+				fmt.Printf("[RPC] WebSocket write failed : %v\n", err)
+				close(c.failed)
+				c.conn.Close() // readLoop도 종료됨
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package rpcserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"simple_p2p_client/mediator"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketNewHeadsSubscription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(ServeWebSocket))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("failed to dial websocket: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// 1. newHeads 구독
+	conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "subscribe", "params": []string{"newHeads"}})
+	var subscribed struct {
+		Result string `json:"result"`
+	}
+	if err := conn.ReadJSON(&subscribed); err != nil || subscribed.Result == "" {
+		t.Fatalf("failed to subscribe: %v %+v", err, subscribed)
+	}
+
+	// 2. 새 헤드 이벤트 발행
+	mediator.GetMediatorInstance().Publish(mediator.ChainHeadEvent{
+		Number:  2,
+		Hash:    "abcd",
+		Payload: `{"number":2,"hash":"abcd","parentHash":"1234","timestamp":10,"merkleRoot":"ef","miner":"0xde589C867174C349d00e9b582867aF5c13A74679"}`,
+	})
+
+	// 3. 알림 확인
+	var notification struct {
+		Method string `json:"method"`
+		Params struct {
+			Subscription string          `json:"subscription"`
+			Result       json.RawMessage `json:"result"`
+		} `json:"params"`
+	}
+	if err := conn.ReadJSON(&notification); err != nil {
+		t.Fatalf("failed to read notification: %v", err)
+	}
+
+	var header EthHeader
+	json.Unmarshal(notification.Params.Result, &header)
+	if notification.Method != "subscription" || notification.Params.Subscription != subscribed.Result {
+		t.Errorf("unexpected notification: %+v", notification)
+	}
+	if header.Number != "0x2" || header.Hash != "0xabcd" {
+		t.Errorf("unexpected header: %+v", header)
+	}
+}
+
+func TestWebSocketRejectsUnknownSubscription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(ServeWebSocket))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("failed to dial websocket: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "subscribe", "params": []string{"logs"}})
+	var res struct {
+		Error *ethError `json:"error"`
+	}
+	if err := conn.ReadJSON(&res); err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if res.Error == nil || res.Error.Code != ethErrInvalidParams {
+		t.Errorf("expected invalid params error, got %+v", res.Error)
+	}
+}
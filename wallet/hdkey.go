@@ -0,0 +1,108 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// BIP-32 강화(hardened) 인덱스 시작값
+const HardenedOffset uint32 = 0x80000000
+
+// BIP-32 확장 개인키 (개인키 + 체인 코드)
+type extendedKey struct {
+	key       []byte // 32바이트 개인키
+	chainCode []byte // 32바이트 체인 코드
+}
+
+// 시드로 마스터 키 생성 (HMAC-SHA512, 키는 "Bitcoin seed")
+func newMasterKey(seed []byte) (*extendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed length : %d", len(seed))
+	}
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	if !isValidPrivateKey(sum[:32]) {
+		return nil, fmt.Errorf("invalid master key")
+	}
+	return &extendedKey{key: sum[:32], chainCode: sum[32:]}, nil
+}
+
+// 자식 개인키 유도 (index >= HardenedOffset면 강화 유도)
+func (k *extendedKey) child(index uint32) (*extendedKey, error) {
+	data := make([]byte, 0, 37)
+	if index >= HardenedOffset {
+		data = append(data, 0x00)
+		data = append(data, k.key...)
+	} else {
+		privateKey, err := crypto.ToECDSA(k.key)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, crypto.CompressPubkey(&privateKey.PublicKey)...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	// 자식 키 = (IL + 부모 키) mod n, IL >= n이거나 결과가 0이면 사용할 수 없는 인덱스
+	curveOrder := crypto.S256().Params().N
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(curveOrder) >= 0 {
+		return nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	childKey := il.Add(il, new(big.Int).SetBytes(k.key))
+	childKey.Mod(childKey, curveOrder)
+	if childKey.Sign() == 0 {
+		return nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+
+	return &extendedKey{key: childKey.FillBytes(make([]byte, 32)), chainCode: sum[32:]}, nil
+}
+
+func (k *extendedKey) privateKey() (*ecdsa.PrivateKey, error) {
+	return crypto.ToECDSA(k.key)
+}
+
+func isValidPrivateKey(key []byte) bool {
+	value := new(big.Int).SetBytes(key)
+	return value.Sign() > 0 && value.Cmp(crypto.S256().Params().N) < 0
+}
+
+// "m/44'/60'/0'/0/0" 형태의 경로를 인덱스 목록으로 변환 (' 또는 h는 강화 유도)
+func ParseDerivationPath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, fmt.Errorf("derivation path must start with m : %s", path)
+	}
+
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h")
+		if hardened {
+			part = part[:len(part)-1]
+		}
+		value, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(value) >= HardenedOffset {
+			return nil, fmt.Errorf("invalid derivation path component %q in %s", part, path)
+		}
+		index := uint32(value)
+		if hardened {
+			index += HardenedOffset
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
@@ -0,0 +1,126 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"simple_p2p_client/account"
+	"simple_p2p_client/keystore"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// MetaMask, geth와 같은 이더리움 기본 경로 (BIP-44, 마지막 인덱스가 계정 번호)
+const DefaultBasePath = "m/44'/60'/0'/0"
+
+// 니모닉에서 유도한 계정
+type Account struct {
+	Path       string
+	Address    string
+	PrivateKey *ecdsa.PrivateKey
+}
+
+// BIP-39 니모닉 시드로 키를 유도하는 HD 지갑
+type Wallet struct {
+	master *extendedKey
+}
+
+// 새 BIP-39 니모닉 생성 (words : 12 또는 24)
+func NewMnemonic(words int) (string, error) {
+	var bitSize int
+	switch words {
+	case 12:
+		bitSize = 128
+	case 24:
+		bitSize = 256
+	default:
+		return "", fmt.Errorf("mnemonic must be 12 or 24 words, got %d", words)
+	}
+
+	entropy, err := bip39.NewEntropy(bitSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate entropy : %v", err)
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// 니모닉과 passphrase(BIP-39 추가 비밀번호, 없으면 "")로 지갑 생성
+func NewFromMnemonic(mnemonic, passphrase string) (*Wallet, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic : %v", err)
+	}
+
+	master, err := newMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	return &Wallet{master: master}, nil
+}
+
+// 기본 경로의 index번째 계정 경로
+func AccountPath(index uint32) string {
+	return fmt.Sprintf("%s/%d", DefaultBasePath, index)
+}
+
+// 경로의 키와 주소 유도
+func (w *Wallet) Derive(path string) (*Account, error) {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := w.master
+	for _, index := range indexes {
+		if key, err = key.child(index); err != nil {
+			return nil, err
+		}
+	}
+
+	privateKey, err := key.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	address, err := account.PublicKeyToAddress(crypto.FromECDSAPub(&privateKey.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	return &Account{Path: path, Address: address, PrivateKey: privateKey}, nil
+}
+
+// 기본 경로의 start번째부터 count개 계정 유도
+func (w *Wallet) DeriveAccounts(start, count uint32) ([]*Account, error) {
+	accounts := make([]*Account, 0, count)
+	for i := uint32(0); i < count; i++ {
+		derived, err := w.Derive(AccountPath(start + i))
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, derived)
+	}
+	return accounts, nil
+}
+
+// 유도한 계정들을 키 저장소에 암호화해 저장 (이미 있는 계정은 건너뜀), 저장된 계정 주소 반환
+func (w *Wallet) ImportToKeyStore(ks *keystore.KeyStore, start, count uint32, password string) ([]string, error) {
+	accounts, err := w.DeriveAccounts(start, count)
+	if err != nil {
+		return nil, err
+	}
+
+	imported := []string{}
+	for _, derived := range accounts {
+		address, err := ks.ImportECDSA(derived.PrivateKey, password)
+		if errors.Is(err, keystore.ErrExists) {
+			continue
+		}
+		if err != nil {
+			return imported, fmt.Errorf("failed to import %s : %v", derived.Path, err)
+		}
+		imported = append(imported, address)
+	}
+	return imported, nil
+}
@@ -0,0 +1,62 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+
+	"simple_p2p_client/keystore"
+)
+
+// BIP-39 테스트 니모닉 : MetaMask에서 같은 니모닉으로 만든 계정 주소와 비교
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestDeriveMetaMaskAddresses(t *testing.T) {
+	w, err := NewFromMnemonic(testMnemonic, "")
+	if err != nil {
+		t.Fatalf("NewFromMnemonic failed: %v", err)
+	}
+
+	accounts, err := w.DeriveAccounts(0, 2)
+	if err != nil {
+		t.Fatalf("DeriveAccounts failed: %v", err)
+	}
+
+	expected := []string{
+		"0x9858EfFD232B4033E47d90003D41EC34EcaEda94",
+		"0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0",
+	}
+	for i, derived := range accounts {
+		if !strings.EqualFold(derived.Address, expected[i]) {
+			t.Errorf("account %d (%s) : expected %s, got %s", i, derived.Path, expected[i], derived.Address)
+		}
+	}
+
+	if _, err := NewFromMnemonic("abandon abandon abandon", ""); err == nil {
+		t.Errorf("expected invalid mnemonic error")
+	}
+	if _, err := ParseDerivationPath("44'/60'/0'/0/0"); err == nil {
+		t.Errorf("expected error for path without m")
+	}
+}
+
+func TestImportToKeyStore(t *testing.T) {
+	ks, err := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("NewKeyStore failed: %v", err)
+	}
+	w, err := NewFromMnemonic(testMnemonic, "")
+	if err != nil {
+		t.Fatalf("NewFromMnemonic failed: %v", err)
+	}
+
+	imported, err := w.ImportToKeyStore(ks, 0, 2, "secret")
+	if err != nil || len(imported) != 2 {
+		t.Fatalf("expected 2 imported accounts, got %v (err %v)", imported, err)
+	}
+
+	// 이미 있는 계정은 건너뜀
+	imported, err = w.ImportToKeyStore(ks, 1, 2, "secret")
+	if err != nil || len(imported) != 1 {
+		t.Fatalf("expected only index 2 to be imported, got %v (err %v)", imported, err)
+	}
+}